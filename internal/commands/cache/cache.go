@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"github.com/cirruslabs/cirrus-cli/internal/commands/helpers"
+	ecache "github.com/cirruslabs/cirrus-cli/internal/executor/cache"
+	"github.com/spf13/cobra"
+)
+
+// Cache-related flags.
+var cacheDir string
+var cacheShareWorktrees bool
+var cachePerBranch bool
+
+// Repository detection flags.
+var gitRemote string
+
+func NewCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local cache of the project in the current directory",
+	}
+
+	// These should match the "cirrus run" flags to select the same cache
+	cmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "",
+		"directory where the local cache is stored (defaults to the user's cache directory)")
+	cmd.PersistentFlags().BoolVar(&cacheShareWorktrees, "cache-share-worktrees", false,
+		"manage the local cache shared between all of the worktrees of the project's Git repository")
+	cmd.PersistentFlags().BoolVar(&cachePerBranch, "cache-per-branch", false,
+		"manage the local cache of the current branch of the project's Git repository")
+	helpers.AddGitRemoteFlag(cmd, &gitRemote)
+
+	commands := []*cobra.Command{
+		newLsCmd(),
+		newInfoCmd(),
+		newRmCmd(),
+		newPruneCmd(),
+		newClearCmd(),
+	}
+
+	return helpers.ConsumeSubCommands(cmd, commands)
+}
+
+// openCache opens the same cache that is used by "cirrus run" in the current directory
+// without creating it if it doesn't exist yet.
+func openCache() (*ecache.Local, error) {
+	namespace := ecache.Namespace(".", ecache.NamespaceOptions{
+		ShareWorktrees: cacheShareWorktrees,
+		PerBranch:      cachePerBranch,
+		Remote:         gitRemote,
+	})
+
+	return ecache.Open(cacheDir, namespace)
+}
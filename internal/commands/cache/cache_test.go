@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/cirrus-cli/internal/commands"
+	ecache "github.com/cirruslabs/cirrus-cli/internal/executor/cache"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withTempCacheDir makes os.UserCacheDir() point to a temporary directory.
+func withTempCacheDir(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	for _, name := range []string{"HOME", "XDG_CACHE_HOME"} {
+		oldValue, wasSet := os.LookupEnv(name)
+		name := name
+
+		t.Cleanup(func() {
+			if wasSet {
+				_ = os.Setenv(name, oldValue)
+			} else {
+				_ = os.Unsetenv(name)
+			}
+		})
+
+		if err := os.Setenv(name, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func runCache(t *testing.T, args ...string) (string, error) {
+	buf := bytes.NewBufferString("")
+
+	command := commands.NewRootCmd()
+	command.SetArgs(append([]string{"cache"}, args...))
+	command.SetOut(buf)
+	command.SetErr(buf)
+	err := command.Execute()
+
+	return buf.String(), err
+}
+
+func populateCache(t *testing.T, keys ...string) {
+	c, err := ecache.New("", ecache.Namespace(".", ecache.NamespaceOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range keys {
+		putOp, err := c.Put(key)
+		require.NoError(t, err)
+		_, err = putOp.Write([]byte("some data"))
+		require.NoError(t, err)
+		require.NoError(t, putOp.Finalize())
+	}
+}
+
+// TestCacheCommands ensures that the cache entries can be listed, inspected and removed.
+func TestCacheCommands(t *testing.T) {
+	withTempCacheDir(t)
+	testutil.TempChdir(t)
+
+	populateCache(t, "node_modules-abc", "some/unsafe key")
+
+	output, err := runCache(t, "ls")
+	require.NoError(t, err)
+	assert.Contains(t, output, "node_modules-abc")
+	assert.Contains(t, output, "some/unsafe key")
+
+	output, err = runCache(t, "info", "some/unsafe key")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Key: some/unsafe key")
+	assert.Contains(t, output, "Size: 9 B (9 bytes)")
+
+	_, err = runCache(t, "rm", "some/unsafe key")
+	require.NoError(t, err)
+
+	_, err = runCache(t, "info", "some/unsafe key")
+	require.Error(t, err)
+
+	_, err = runCache(t, "prune")
+	require.Error(t, err)
+
+	output, err = runCache(t, "prune", "--max-size", "1B")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Pruned 1 entries")
+
+	populateCache(t, "another")
+
+	_, err = runCache(t, "clear")
+	require.NoError(t, err)
+
+	output, err = runCache(t, "ls")
+	require.NoError(t, err)
+	assert.NotContains(t, output, "another")
+}
+
+// TestCacheCommandsDontCreateCache ensures that inspecting a cache that doesn't exist yet doesn't create it.
+func TestCacheCommandsDontCreateCache(t *testing.T) {
+	withTempCacheDir(t)
+	testutil.TempChdir(t)
+
+	cacheDir := testutil.TempDir(t)
+
+	_, err := runCache(t, "ls", "--cache-dir", cacheDir)
+	require.NoError(t, err)
+
+	_, err = runCache(t, "info", "--cache-dir", cacheDir, "key")
+	require.Error(t, err)
+
+	assert.NoDirExists(t, filepath.Join(cacheDir, "cirrus"))
+}
+
+// TestCacheClearLegacy ensures that the cache left by the older versions of the CLI can be removed.
+func TestCacheClearLegacy(t *testing.T) {
+	withTempCacheDir(t)
+	testutil.TempChdir(t)
+
+	cacheDir := testutil.TempDir(t)
+
+	legacy, err := ecache.New(cacheDir, ecache.LegacyNamespace("."))
+	require.NoError(t, err)
+	putOp, err := legacy.Put("key")
+	require.NoError(t, err)
+	require.NoError(t, putOp.Finalize())
+
+	_, err = runCache(t, "clear", "--cache-dir", cacheDir, "--legacy")
+	require.NoError(t, err)
+
+	assert.NoDirExists(t, filepath.Join(cacheDir, "cirrus", "projects", ecache.LegacyNamespace(".")))
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	ecache "github.com/cirruslabs/cirrus-cli/internal/executor/cache"
+	"github.com/spf13/cobra"
+)
+
+// Clear-related flags.
+var clearLegacy bool
+
+func clearAll(cmd *cobra.Command, args []string) error {
+	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
+	cmd.SilenceUsage = true
+
+	if clearLegacy {
+		return ecache.RemoveLegacyNamespace(cacheDir, ".")
+	}
+
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	return c.Clear()
+}
+
+func newClearCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cache entries",
+		Args:  cobra.NoArgs,
+		RunE:  clearAll,
+	}
+
+	cmd.PersistentFlags().BoolVar(&clearLegacy, "legacy", false,
+		"remove the cache left by the older CLI versions, which was shared by all of the projects "+
+			"with the same directory name as the current one")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func info(cmd *cobra.Command, args []string) error {
+	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
+	cmd.SilenceUsage = true
+
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	entry, err := c.Info(args[0])
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, args[0])
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Key: %s\n", entry.Key)
+	fmt.Fprintf(out, "Path: %s\n", entry.Path)
+	fmt.Fprintf(out, "Size: %s (%d bytes)\n", humanize.Bytes(uint64(entry.Size)), entry.Size)
+	fmt.Fprintf(out, "Created: %s (%s)\n", entry.CreatedAt.Format(time.RFC3339), humanize.Time(entry.CreatedAt))
+	fmt.Fprintf(out, "Last accessed: %s (%s)\n", entry.AccessedAt.Format(time.RFC3339),
+		humanize.Time(entry.AccessedAt))
+
+	return nil
+}
+
+func newInfoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "info KEY",
+		Short: "Show the details of a cache entry",
+		Args:  cobra.ExactArgs(1),
+		RunE:  info,
+	}
+
+	return cmd
+}
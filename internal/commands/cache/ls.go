@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"text/tabwriter"
+)
+
+func ls(cmd *cobra.Command, args []string) error {
+	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
+	cmd.SilenceUsage = true
+
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+
+	const padding = 2
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, padding, ' ', 0)
+
+	fmt.Fprintln(w, "KEY\tSIZE\tCREATED\tLAST ACCESSED")
+
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Key, humanize.Bytes(uint64(entry.Size)),
+			humanize.Time(entry.CreatedAt), humanize.Time(entry.AccessedAt))
+	}
+
+	return w.Flush()
+}
+
+func newLsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List cache entries",
+		Args:  cobra.NoArgs,
+		RunE:  ls,
+	}
+
+	return cmd
+}
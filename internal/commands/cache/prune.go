@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var ErrPrune = errors.New("failed to prune the cache")
+
+// Prune-related flags.
+var olderThan time.Duration
+var maxSize string
+
+func prune(cmd *cobra.Command, args []string) error {
+	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
+	cmd.SilenceUsage = true
+
+	if olderThan == 0 && maxSize == "" {
+		return fmt.Errorf("%w: please specify --older-than, --max-size or both", ErrPrune)
+	}
+
+	var maxSizeBytes uint64
+
+	if maxSize != "" {
+		var err error
+
+		maxSizeBytes, err = humanize.ParseBytes(maxSize)
+		if err != nil {
+			return fmt.Errorf("%w: invalid --max-size value %q: %v", ErrPrune, maxSize, err)
+		}
+	}
+
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	pruned, err := c.Prune(olderThan, maxSizeBytes)
+	if err != nil {
+		return err
+	}
+
+	var prunedBytes uint64
+	for _, entry := range pruned {
+		prunedBytes += uint64(entry.Size)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d entries, freeing %s\n", len(pruned), humanize.Bytes(prunedBytes))
+
+	return nil
+}
+
+func newPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove stale cache entries",
+		Args:  cobra.NoArgs,
+		RunE:  prune,
+	}
+
+	cmd.PersistentFlags().DurationVar(&olderThan, "older-than", 0,
+		"remove the entries that weren't accessed for the specified duration (e.g. 168h)")
+	cmd.PersistentFlags().StringVar(&maxSize, "max-size", "",
+		"remove the least recently accessed entries until the cache fits into the specified size (e.g. 5GB)")
+
+	return cmd
+}
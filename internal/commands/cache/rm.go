@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+func rm(cmd *cobra.Command, args []string) error {
+	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
+	cmd.SilenceUsage = true
+
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range args {
+		if err := c.Delete(key); err != nil {
+			return fmt.Errorf("%w: %q", err, key)
+		}
+	}
+
+	return nil
+}
+
+func newRmCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm KEY...",
+		Short: "Remove cache entries",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  rm,
+	}
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+package cliconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ProjectFileName is the name of the project-level configuration file, which
+// is meant to be ignored by Git and takes precedence over the user-level one.
+const ProjectFileName = ".cirrus.local.yml"
+
+var ErrInvalidConfig = errors.New("invalid CLI configuration")
+
+// Config provides the defaults for the "cirrus run" flags, which are
+// only used when the corresponding flags are not specified explicitly.
+type Config struct {
+	// Environment is overridden by the variables from --env-file and -e flags
+	Environment map[string]string `yaml:"environment"`
+
+	Output                  string `yaml:"output"`
+	ContainerBackend        string `yaml:"container_backend"`
+	ContainerLazyPull       *bool  `yaml:"container_lazy_pull"`
+	DockerfileImageTemplate string `yaml:"dockerfile_image_template"`
+	CacheMaxSize            string `yaml:"cache_max_size"`
+}
+
+// UserPath returns the path to the user-level configuration file,
+// which is $XDG_CONFIG_HOME/cirrus/config.yml or ~/.config/cirrus/config.yml.
+func UserPath() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		configDir = filepath.Join(homeDir, ".config")
+	}
+
+	return filepath.Join(configDir, "cirrus", "config.yml"), nil
+}
+
+// Load reads the user-level configuration and then the configuration of the project
+// in the specified directory, with the latter taking precedence. Missing files are ignored.
+func Load(projectDir string) (*Config, error) {
+	config := &Config{
+		Environment: make(map[string]string),
+	}
+
+	userPath, err := UserPath()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range []string{userPath, filepath.Join(projectDir, ProjectFileName)} {
+		fileConfig, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if fileConfig != nil {
+			config.merge(fileConfig)
+		}
+	}
+
+	return config, nil
+}
+
+func loadFile(path string) (*Config, error) {
+	configBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var config Config
+
+	// Catch the typos in the option names
+	decoder := yaml.NewDecoder(bytes.NewReader(configBytes))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+
+	return &config, nil
+}
+
+func (config *Config) merge(other *Config) {
+	for key, value := range other.Environment {
+		config.Environment[key] = value
+	}
+
+	if other.Output != "" {
+		config.Output = other.Output
+	}
+	if other.ContainerBackend != "" {
+		config.ContainerBackend = other.ContainerBackend
+	}
+	if other.ContainerLazyPull != nil {
+		config.ContainerLazyPull = other.ContainerLazyPull
+	}
+	if other.DockerfileImageTemplate != "" {
+		config.DockerfileImageTemplate = other.DockerfileImageTemplate
+	}
+	if other.CacheMaxSize != "" {
+		config.CacheMaxSize = other.CacheMaxSize
+	}
+}
@@ -0,0 +1,87 @@
+package cliconfig_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/cliconfig"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withConfigHome makes the user-level configuration to be read from the specified directory.
+func withConfigHome(t *testing.T, dir string) {
+	oldValue, wasSet := os.LookupEnv("XDG_CONFIG_HOME")
+
+	t.Cleanup(func() {
+		if wasSet {
+			_ = os.Setenv("XDG_CONFIG_HOME", oldValue)
+		} else {
+			_ = os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	})
+
+	require.NoError(t, os.Setenv("XDG_CONFIG_HOME", dir))
+}
+
+func writeConfig(t *testing.T, path string, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+}
+
+// TestLoad ensures that the project-level configuration takes precedence over the user-level one.
+func TestLoad(t *testing.T) {
+	configHome := testutil.TempDir(t)
+	projectDir := testutil.TempDir(t)
+	withConfigHome(t, configHome)
+
+	// No configuration at all
+	config, err := cliconfig.Load(projectDir)
+	require.NoError(t, err)
+	assert.Empty(t, config.Environment)
+	assert.Nil(t, config.ContainerLazyPull)
+
+	writeConfig(t, filepath.Join(configHome, "cirrus", "config.yml"), `environment:
+  USER_ONLY: user
+  OVERRIDDEN: user
+output: simple
+container_backend: podman
+container_lazy_pull: true
+cache_max_size: 10GB
+`)
+	writeConfig(t, filepath.Join(projectDir, cliconfig.ProjectFileName), `environment:
+  OVERRIDDEN: project
+  NUMBER: 42
+container_backend: docker
+dockerfile_image_template: registry.example.com/%s:latest
+`)
+
+	config, err = cliconfig.Load(projectDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"USER_ONLY":  "user",
+		"OVERRIDDEN": "project",
+		"NUMBER":     "42",
+	}, config.Environment)
+	assert.Equal(t, "simple", config.Output)
+	assert.Equal(t, "docker", config.ContainerBackend)
+	require.NotNil(t, config.ContainerLazyPull)
+	assert.True(t, *config.ContainerLazyPull)
+	assert.Equal(t, "registry.example.com/%s:latest", config.DockerfileImageTemplate)
+	assert.Equal(t, "10GB", config.CacheMaxSize)
+}
+
+// TestLoadUnknownOption ensures that the typos in the configuration are reported.
+func TestLoadUnknownOption(t *testing.T) {
+	withConfigHome(t, testutil.TempDir(t))
+	projectDir := testutil.TempDir(t)
+
+	writeConfig(t, filepath.Join(projectDir, cliconfig.ProjectFileName), "container-backend: docker\n")
+
+	_, err := cliconfig.Load(projectDir)
+	assert.True(t, errors.Is(err, cliconfig.ErrInvalidConfig))
+}
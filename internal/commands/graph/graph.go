@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/helpers"
+	eenvironment "github.com/cirruslabs/cirrus-cli/internal/executor/environment"
+	"github.com/cirruslabs/cirrus-cli/pkg/parser"
+	"github.com/cirruslabs/cirrus-cli/pkg/parser/parsererror"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// General flags.
+var format string
+var environment []string
+var baseRef string
+var event eenvironment.Event
+var gitRemote string
+
+func graph(cmd *cobra.Command, args []string) error {
+	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
+	cmd.SilenceUsage = true
+
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
+	// Craft the environment
+	projectEnvironment := eenvironment.ProjectSpecificWithRemote(".", gitRemote)
+	baseEnvironment := eenvironment.Merge(
+		eenvironment.Static(),
+		eenvironment.BuildID(),
+		event.Apply(projectEnvironment),
+	)
+	userSpecifiedEnvironment := helpers.EnvArgsToMap(environment)
+	resultingEnvironment := eenvironment.Merge(baseEnvironment, userSpecifiedEnvironment)
+
+	// Figure out which files were changed for the changesInclude() to work, but only if it's used
+	lazyAffectedFiles := helpers.NewLazyAffectedFiles(".", baseRef, gitRemote, resultingEnvironment)
+
+	// Retrieve the combined YAML configuration
+	combinedYAML, err := helpers.ReadCombinedConfig(cmd.Context(), resultingEnvironment, lazyAffectedFiles)
+	if err != nil {
+		return err
+	}
+
+	affectedFiles, err := lazyAffectedFiles.ForYAML(combinedYAML)
+	if err != nil {
+		return err
+	}
+
+	// Parse
+	p := parser.New(
+		parser.WithEnvironment(eenvironment.Merge(event.Variables(projectEnvironment), userSpecifiedEnvironment)),
+		parser.WithAffectedFiles(affectedFiles),
+		parser.WithMissingInstancesAllowed(),
+	)
+	result, err := p.Parse(cmd.Context(), combinedYAML)
+	if err != nil {
+		if re, ok := err.(*parsererror.Rich); ok {
+			fmt.Print(re.ContextLines())
+		}
+
+		return err
+	}
+
+	return Write(cmd.OutOrStdout(), result, format)
+}
+
+func NewGraphCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Print the task dependency graph",
+		RunE:  graph,
+	}
+
+	// General flags
+	cmd.PersistentFlags().StringVar(&format, "format", FormatDOT,
+		fmt.Sprintf("graph format, supported values: %s", strings.Join(Formats(), ", ")))
+	cmd.PersistentFlags().StringArrayVarP(&environment, "environment", "e", []string{},
+		"set (-e A=B) or pass-through (-e A) an environment variable")
+	cmd.PersistentFlags().StringVar(&baseRef, "base-ref", "",
+		"branch or commit to compare against when evaluating changesInclude() (defaults to $CIRRUS_BASE_BRANCH)")
+
+	// Event emulation flags
+	helpers.AddEventFlags(cmd, &event)
+
+	// Repository detection flags
+	helpers.AddGitRemoteFlag(cmd, &gitRemote)
+
+	return cmd
+}
@@ -0,0 +1,50 @@
+package graph_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/cirrus-cli/internal/commands"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func runGraph(t *testing.T, args ...string) string {
+	buf := bytes.NewBufferString("")
+
+	command := commands.NewRootCmd()
+	command.SetArgs(append([]string{"graph"}, args...))
+	command.SetOut(buf)
+	command.SetErr(buf)
+	require.NoError(t, command.Execute())
+
+	return buf.String()
+}
+
+// TestGraphDOT ensures that dependencies, labels and disabled tasks are present in the DOT output.
+func TestGraphDOT(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/dependencies")
+
+	output := runGraph(t)
+
+	assert.Contains(t, output, "digraph cirrus {")
+	assert.Contains(t, output, `task0 [label="build"];`)
+	assert.Contains(t, output, `task1 [label="test\nVERSION:1.14"];`)
+	assert.Contains(t, output, "task0 -> task1;")
+	assert.Contains(t, output, "task0 -> task2;")
+	assert.Contains(t, output, `disabled0 [label="deploy\n(disabled by only_if)", style=dashed, fontcolor=gray];`)
+	assert.Contains(t, output, "task1 -> disabled0 [style=dashed];")
+}
+
+// TestGraphMermaid ensures that the tasks enabled by the emulated event are rendered as enabled.
+func TestGraphMermaid(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/dependencies")
+
+	output := runGraph(t, "--format", "mermaid", "--tag", "v1.0.0")
+
+	assert.Contains(t, output, "graph TD")
+	assert.Contains(t, output, `task3["deploy"]`)
+	assert.Contains(t, output, "task1 --> task3")
+	assert.Contains(t, output, "task2 --> task3")
+	assert.NotContains(t, output, "disabled")
+}
@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/cirrus-cli/pkg/parser"
+	"io"
+	"strings"
+)
+
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported graph format")
+
+type node struct {
+	ID       string
+	Lines    []string
+	Disabled bool
+}
+
+type edge struct {
+	From, To string
+	Disabled bool
+}
+
+type taskGraph struct {
+	Nodes []node
+	Edges []edge
+}
+
+func taskNodeID(id int64) string {
+	return fmt.Sprintf("task%d", id)
+}
+
+func disabledNodeID(index int) string {
+	return fmt.Sprintf("disabled%d", index)
+}
+
+// newGraph converts the parsing result into a list of nodes and edges. Tasks filtered out by their
+// "only_if" expressions are included too, but marked as disabled, along with their dependencies.
+func newGraph(result *parser.Result) *taskGraph {
+	g := &taskGraph{}
+
+	for _, task := range result.Tasks {
+		lines := []string{task.Name}
+		if task.Metadata != nil {
+			lines = append(lines, task.Metadata.UniqueLabels...)
+		}
+
+		g.Nodes = append(g.Nodes, node{ID: taskNodeID(task.LocalGroupId), Lines: lines})
+
+		for _, requiredID := range task.RequiredGroups {
+			g.Edges = append(g.Edges, edge{From: taskNodeID(requiredID), To: taskNodeID(task.LocalGroupId)})
+		}
+	}
+
+	for i, disabledTask := range result.DisabledTasks {
+		nodeID := disabledNodeID(i)
+
+		g.Nodes = append(g.Nodes, node{
+			ID:       nodeID,
+			Lines:    []string{disabledTask.Name, "(disabled by only_if)"},
+			Disabled: true,
+		})
+
+		for _, dependsOnName := range disabledTask.DependsOnNames {
+			for _, from := range findNodes(result, dependsOnName) {
+				g.Edges = append(g.Edges, edge{From: from, To: nodeID, Disabled: true})
+			}
+		}
+
+		for _, requiredByID := range disabledTask.RequiredBy {
+			g.Edges = append(g.Edges, edge{From: nodeID, To: taskNodeID(requiredByID), Disabled: true})
+		}
+	}
+
+	return g
+}
+
+func findNodes(result *parser.Result, name string) (nodeIDs []string) {
+	for _, task := range result.Tasks {
+		if task.Name == name {
+			nodeIDs = append(nodeIDs, taskNodeID(task.LocalGroupId))
+		}
+	}
+
+	for i, disabledTask := range result.DisabledTasks {
+		if disabledTask.Name == name || disabledTask.Alias == name {
+			nodeIDs = append(nodeIDs, disabledNodeID(i))
+		}
+	}
+
+	return nodeIDs
+}
+
+// Write renders the task dependency graph from the parsing result in the specified format.
+func Write(w io.Writer, result *parser.Result, format string) error {
+	g := newGraph(result)
+
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, g)
+	case FormatMermaid:
+		return writeMermaid(w, g)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+func writeDOT(w io.Writer, g *taskGraph) error {
+	var sb strings.Builder
+
+	sb.WriteString("digraph cirrus {\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for _, n := range g.Nodes {
+		attributes := fmt.Sprintf("label=%q", strings.Join(n.Lines, "\n"))
+		if n.Disabled {
+			attributes += ", style=dashed, fontcolor=gray"
+		}
+
+		fmt.Fprintf(&sb, "  %s [%s];\n", n.ID, attributes)
+	}
+
+	for _, e := range g.Edges {
+		if e.Disabled {
+			fmt.Fprintf(&sb, "  %s -> %s [style=dashed];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&sb, "  %s -> %s;\n", e.From, e.To)
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func writeMermaid(w io.Writer, g *taskGraph) error {
+	var sb strings.Builder
+
+	sb.WriteString("graph TD\n")
+
+	var hasDisabled bool
+
+	for _, n := range g.Nodes {
+		var escapedLines []string
+		for _, line := range n.Lines {
+			escapedLines = append(escapedLines, strings.ReplaceAll(line, "\"", "#quot;"))
+		}
+
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", n.ID, strings.Join(escapedLines, "<br/>"))
+
+		if n.Disabled {
+			fmt.Fprintf(&sb, "  class %s disabled\n", n.ID)
+			hasDisabled = true
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.Disabled {
+			fmt.Fprintf(&sb, "  %s -.-> %s\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&sb, "  %s --> %s\n", e.From, e.To)
+		}
+	}
+
+	if hasDisabled {
+		sb.WriteString("  classDef disabled stroke-dasharray: 5 5, color: gray\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// Formats returns the supported graph formats.
+func Formats() []string {
+	return []string{FormatDOT, FormatMermaid}
+}
@@ -0,0 +1,220 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"os"
+	"sort"
+	"strings"
+)
+
+var ErrAffectedFilesFailed = errors.New("failed to determine affected files")
+
+// LazyAffectedFiles only detects the affected files (see DetectAffectedFiles()) once they're
+// actually needed, since the Git status is slow to compute for the large repositories and most
+// of the configurations don't use changesInclude() at all.
+type LazyAffectedFiles struct {
+	projectDir  string
+	userBaseRef string
+	remote      string
+	env         map[string]string
+
+	detected      bool
+	affectedFiles []string
+	err           error
+}
+
+func NewLazyAffectedFiles(
+	projectDir string,
+	userBaseRef string,
+	remote string,
+	env map[string]string,
+) *LazyAffectedFiles {
+	return &LazyAffectedFiles{
+		projectDir:  projectDir,
+		userBaseRef: userBaseRef,
+		remote:      remote,
+		env:         env,
+	}
+}
+
+// Get returns the affected files, detecting them on the first call.
+func (lazy *LazyAffectedFiles) Get() ([]string, error) {
+	if !lazy.detected {
+		lazy.affectedFiles, lazy.err = DetectAffectedFiles(lazy.projectDir, lazy.userBaseRef, lazy.remote, lazy.env)
+		lazy.detected = true
+	}
+
+	return lazy.affectedFiles, lazy.err
+}
+
+// ForYAML returns the affected files if the YAML configuration uses changesInclude() and nothing otherwise.
+func (lazy *LazyAffectedFiles) ForYAML(config string) ([]string, error) {
+	if !strings.Contains(config, "changesInclude") {
+		return nil, nil
+	}
+
+	return lazy.Get()
+}
+
+// DetectAffectedFiles is like AffectedFiles, but when the base reference is not specified
+// by the user, falls back to the CIRRUS_BASE_BRANCH variable from the build environment
+// or from the process environment. Since the latter might reference a branch that doesn't
+// exist locally, only the uncommitted changes are considered if it cannot be resolved.
+func DetectAffectedFiles(
+	projectDir string,
+	userBaseRef string,
+	remote string,
+	env map[string]string,
+) ([]string, error) {
+	if userBaseRef != "" {
+		return AffectedFiles(projectDir, userBaseRef, remote)
+	}
+
+	baseBranch, ok := env["CIRRUS_BASE_BRANCH"]
+	if !ok {
+		baseBranch = os.Getenv("CIRRUS_BASE_BRANCH")
+	}
+
+	if baseBranch != "" {
+		if affectedFiles, err := AffectedFiles(projectDir, baseBranch, remote); err == nil {
+			return affectedFiles, nil
+		}
+	}
+
+	return AffectedFiles(projectDir, "", remote)
+}
+
+// AffectedFiles returns the files that were changed in the working tree and, if the base reference
+// is specified, the files changed in HEAD since it diverged from the base reference.
+//
+// Base reference that doesn't exist locally is looked up in the specified remote.
+//
+// Projects that are not Git repositories are considered to have no changes, unless the base
+// reference is specified, in which case an error is returned.
+func AffectedFiles(projectDir string, baseRef string, remote string) ([]string, error) {
+	repo, err := git.PlainOpen(projectDir)
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) && baseRef == "" {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrAffectedFilesFailed, err)
+	}
+
+	affectedFiles := make(map[string]struct{})
+
+	if baseRef != "" {
+		if err := addCommittedChanges(affectedFiles, repo, baseRef, remote); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrAffectedFilesFailed, err)
+		}
+	}
+
+	if err := addUncommittedChanges(affectedFiles, repo); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAffectedFilesFailed, err)
+	}
+
+	var result []string
+	for affectedFile := range affectedFiles {
+		result = append(result, affectedFile)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+func addCommittedChanges(
+	affectedFiles map[string]struct{},
+	repo *git.Repository,
+	baseRef string,
+	remote string,
+) error {
+	// Base reference might only be available as a remote branch (e.g. when it's a fresh clone)
+	baseHash, err := repo.ResolveRevision(plumbing.Revision(baseRef))
+	if err != nil {
+		var remoteErr error
+
+		baseHash, remoteErr = repo.ResolveRevision(plumbing.Revision(remote + "/" + baseRef))
+		if remoteErr != nil {
+			return fmt.Errorf("failed to resolve base reference %q: %v", baseRef, err)
+		}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	baseCommit, err := repo.CommitObject(*baseHash)
+	if err != nil {
+		return err
+	}
+
+	// Only consider the changes made since HEAD diverged from the base
+	mergeBases, err := headCommit.MergeBase(baseCommit)
+	if err != nil {
+		return err
+	}
+	if len(mergeBases) != 0 {
+		baseCommit = mergeBases[0]
+	}
+
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return err
+	}
+
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return err
+	}
+
+	changes, err := object.DiffTree(baseTree, headTree)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.From.Name != "" {
+			affectedFiles[change.From.Name] = struct{}{}
+		}
+		if change.To.Name != "" {
+			affectedFiles[change.To.Name] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+func addUncommittedChanges(affectedFiles map[string]struct{}, repo *git.Repository) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		// Bare repositories have no working tree
+		if errors.Is(err, git.ErrIsBareRepository) {
+			return nil
+		}
+
+		return err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+
+	for path, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+			affectedFiles[path] = struct{}{}
+		}
+	}
+
+	return nil
+}
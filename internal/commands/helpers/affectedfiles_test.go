@@ -0,0 +1,123 @@
+package helpers_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/helpers"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func commitFile(t *testing.T, dir string, workTree *git.Worktree, name string) plumbing.Hash {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0600))
+
+	_, err := workTree.Add(name)
+	require.NoError(t, err)
+
+	commitHash, err := workTree.Commit("Add "+name, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Charlie Root",
+			Email: "root@localhost",
+			When:  time.Now(),
+		},
+	})
+	require.NoError(t, err)
+
+	return commitHash
+}
+
+// TestAffectedFiles ensures that both the committed and uncommitted changes are taken into account.
+func TestAffectedFiles(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	workTree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	// Create a base branch that HEAD will diverge from
+	baseHash := commitFile(t, dir, workTree, "base.txt")
+	ref := plumbing.NewHashReference("refs/heads/base", baseHash)
+	require.NoError(t, repo.Storer.SetReference(ref))
+
+	commitFile(t, dir, workTree, "committed.txt")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "uncommitted.txt"), []byte("test\n"), 0600))
+
+	affectedFiles, err := helpers.AffectedFiles(dir, "base", "origin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"committed.txt", "uncommitted.txt"}, affectedFiles)
+
+	affectedFiles, err = helpers.AffectedFiles(dir, baseHash.String(), "origin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"committed.txt", "uncommitted.txt"}, affectedFiles)
+
+	// Without a base only the uncommitted changes are considered
+	affectedFiles, err = helpers.AffectedFiles(dir, "", "origin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"uncommitted.txt"}, affectedFiles)
+
+	_, err = helpers.AffectedFiles(dir, "non-existent", "origin")
+	assert.True(t, errors.Is(err, helpers.ErrAffectedFilesFailed))
+
+	// Base reference that only exists in the chosen remote (e.g. in a fresh clone)
+	ref = plumbing.NewHashReference("refs/remotes/upstream/main", baseHash)
+	require.NoError(t, repo.Storer.SetReference(ref))
+
+	affectedFiles, err = helpers.AffectedFiles(dir, "main", "upstream")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"committed.txt", "uncommitted.txt"}, affectedFiles)
+
+	_, err = helpers.AffectedFiles(dir, "main", "origin")
+	assert.True(t, errors.Is(err, helpers.ErrAffectedFilesFailed))
+}
+
+// TestAffectedFilesNoRepository ensures that projects outside of Git are considered to have no changes.
+func TestAffectedFilesNoRepository(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	affectedFiles, err := helpers.AffectedFiles(dir, "", "origin")
+	require.NoError(t, err)
+	assert.Empty(t, affectedFiles)
+
+	_, err = helpers.AffectedFiles(dir, "main", "origin")
+	assert.True(t, errors.Is(err, helpers.ErrAffectedFilesFailed))
+}
+
+// TestDetectAffectedFiles ensures that an unresolvable CIRRUS_BASE_BRANCH is not an error.
+func TestDetectAffectedFiles(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	affectedFiles, err := helpers.DetectAffectedFiles(dir, "", "origin", map[string]string{
+		"CIRRUS_BASE_BRANCH": "non-existent",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, affectedFiles)
+
+	_, err = helpers.DetectAffectedFiles(dir, "non-existent", "origin", map[string]string{})
+	assert.True(t, errors.Is(err, helpers.ErrAffectedFilesFailed))
+}
+
+// TestLazyAffectedFiles ensures that the affected files are only detected
+// for the YAML configurations that use changesInclude().
+func TestLazyAffectedFiles(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	// Base reference cannot be resolved outside of a Git repository, so an error
+	// is returned each time the affected files are actually detected
+	lazyAffectedFiles := helpers.NewLazyAffectedFiles(dir, "main", "origin", map[string]string{})
+
+	affectedFiles, err := lazyAffectedFiles.ForYAML("task:\n  script: true\n")
+	require.NoError(t, err)
+	assert.Empty(t, affectedFiles)
+
+	_, err = lazyAffectedFiles.ForYAML("task:\n  only_if: changesInclude('*.go')\n  script: true\n")
+	assert.True(t, errors.Is(err, helpers.ErrAffectedFilesFailed))
+}
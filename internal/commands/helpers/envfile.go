@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrEnvFileInvalid = errors.New("invalid environment file")
+
+// ReadEnvFile parses the file in the dotenv syntax, which consists of the "KEY=value" lines
+// with optional "export " prefixes, single- or double-quoted values and "#" comments.
+func ReadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, err := parseEnvFileLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+
+		result[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseEnvFileLine(line string) (string, string, error) {
+	line = strings.TrimPrefix(line, "export ")
+
+	equalsSign := strings.Index(line, "=")
+	if equalsSign == -1 {
+		return "", "", fmt.Errorf("%w: expected KEY=value, got %q", ErrEnvFileInvalid, line)
+	}
+
+	key := strings.TrimSpace(line[:equalsSign])
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("%w: invalid variable name %q", ErrEnvFileInvalid, key)
+	}
+
+	value := strings.TrimSpace(line[equalsSign+1:])
+
+	switch {
+	case strings.HasPrefix(value, "'"):
+		// Single-quoted values are taken literally
+		closingQuote := strings.Index(value[1:], "'")
+		if closingQuote == -1 {
+			return "", "", fmt.Errorf("%w: unterminated single-quoted value of %s", ErrEnvFileInvalid, key)
+		}
+
+		return key, value[1 : closingQuote+1], nil
+	case strings.HasPrefix(value, "\""):
+		unquoted, ok := unquoteEnvFileValue(value[1:])
+		if !ok {
+			return "", "", fmt.Errorf("%w: unterminated double-quoted value of %s", ErrEnvFileInvalid, key)
+		}
+
+		return key, unquoted, nil
+	default:
+		// Unquoted values may be followed by a comment
+		if comment := strings.Index(value, " #"); comment != -1 {
+			value = strings.TrimSpace(value[:comment])
+		}
+
+		return key, value, nil
+	}
+}
+
+// unquoteEnvFileValue processes the escape sequences of a double-quoted value
+// up until the closing quote.
+func unquoteEnvFileValue(value string) (string, bool) {
+	var result strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			return result.String(), true
+		case '\\':
+			i++
+			if i == len(value) {
+				return "", false
+			}
+
+			switch value[i] {
+			case 'n':
+				result.WriteByte('\n')
+			case 't':
+				result.WriteByte('\t')
+			default:
+				result.WriteByte(value[i])
+			}
+		default:
+			result.WriteByte(value[i])
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,54 @@
+package helpers_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/helpers"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func writeEnvFile(t *testing.T, contents string) string {
+	path := filepath.Join(testutil.TempDir(t), ".env")
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+
+	return path
+}
+
+// TestReadEnvFile ensures that the common dotenv syntax is supported.
+func TestReadEnvFile(t *testing.T) {
+	path := writeEnvFile(t, `# Comment
+PLAIN=value
+export EXPORTED=exported value
+SPACED = spaced # trailing comment
+EMPTY=
+SINGLE='literal \n $VALUE # not a comment'
+DOUBLE="line\nanother \"quoted\" line" # comment
+HASH=a#b
+`)
+
+	env, err := helpers.ReadEnvFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "exported value",
+		"SPACED":   "spaced",
+		"EMPTY":    "",
+		"SINGLE":   `literal \n $VALUE # not a comment`,
+		"DOUBLE":   "line\nanother \"quoted\" line",
+		"HASH":     "a#b",
+	}, env)
+}
+
+// TestReadEnvFileInvalid ensures that the syntax errors are reported with their location.
+func TestReadEnvFileInvalid(t *testing.T) {
+	for _, contents := range []string{"VALID=1\nINVALID\n", "VALID=1\nQUOTED=\"unterminated\n"} {
+		_, err := helpers.ReadEnvFile(writeEnvFile(t, contents))
+		assert.True(t, errors.Is(err, helpers.ErrEnvFileInvalid))
+		assert.Contains(t, err.Error(), ".env:2")
+	}
+}
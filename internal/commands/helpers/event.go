@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	eenvironment "github.com/cirruslabs/cirrus-cli/internal/executor/environment"
+	"github.com/spf13/cobra"
+)
+
+// AddEventFlags registers the flags that allow emulating a specific CI event.
+func AddEventFlags(cmd *cobra.Command, event *eenvironment.Event) {
+	cmd.PersistentFlags().StringVar(&event.Branch, "branch", "",
+		"emulate a build of the specified branch instead of the one checked out")
+	cmd.PersistentFlags().StringVar(&event.Tag, "tag", "",
+		"emulate a build triggered by pushing the specified tag")
+	cmd.PersistentFlags().StringVar(&event.Cron, "cron", "",
+		"emulate a build triggered by the cron build with the specified name")
+	cmd.PersistentFlags().Int64Var(&event.PR, "pr", 0,
+		"emulate a build of the pull request with the specified number")
+	cmd.PersistentFlags().StringVar(&event.BaseBranch, "base-branch", "",
+		"base branch of the emulated pull request")
+	cmd.PersistentFlags().StringArrayVar(&event.PRLabels, "pr-label", []string{},
+		"label of the emulated pull request (can be specified multiple times)")
+	cmd.PersistentFlags().BoolVar(&event.PRDraft, "pr-draft", false,
+		"emulate a build of a draft pull request")
+}
+
+// AddGitRemoteFlag registers the flag that allows choosing the Git remote from which
+// the repository-related variables are populated and the project's cache is identified.
+func AddGitRemoteFlag(cmd *cobra.Command, remote *string) {
+	cmd.PersistentFlags().StringVar(remote, "git-remote", eenvironment.DefaultRemote,
+		"Git remote to populate the CIRRUS_REPO_* variables from and to identify the project's cache by")
+}
@@ -72,13 +72,29 @@ func ReadYAMLConfig(path string) (string, error) {
 	return string(yamlConfig), nil
 }
 
-func EvaluateStarlarkConfig(ctx context.Context, path string, env map[string]string) (string, error) {
+// EvaluateStarlarkConfig evaluates the Starlark configuration, always detecting the affected files
+// for its changes_include() since it might be called by any of the loaded modules.
+func EvaluateStarlarkConfig(
+	ctx context.Context,
+	path string,
+	env map[string]string,
+	lazyAffectedFiles *LazyAffectedFiles,
+) (string, error) {
 	starlarkSource, err := ioutil.ReadFile(path)
 	if err != nil {
 		return "", err
 	}
 
-	lrk := larker.New(larker.WithFileSystem(local.New(".")), larker.WithEnvironment(env))
+	affectedFiles, err := lazyAffectedFiles.Get()
+	if err != nil {
+		return "", err
+	}
+
+	lrk := larker.New(
+		larker.WithFileSystem(local.New(".")),
+		larker.WithEnvironment(env),
+		larker.WithAffectedFiles(affectedFiles),
+	)
 
 	result, err := lrk.Main(ctx, string(starlarkSource))
 	if err != nil {
@@ -88,7 +104,11 @@ func EvaluateStarlarkConfig(ctx context.Context, path string, env map[string]str
 	return result.YAMLConfig, nil
 }
 
-func ReadCombinedConfig(ctx context.Context, env map[string]string) (string, error) {
+func ReadCombinedConfig(
+	ctx context.Context,
+	env map[string]string,
+	lazyAffectedFiles *LazyAffectedFiles,
+) (string, error) {
 	// Here we read the .cirrus.yaml first so that if the error would arise
 	// and will be inspected it would indicate the preferable extension
 	yamlConfig, yamlErr := ReadYAMLConfig(".cirrus.yaml")
@@ -103,7 +123,7 @@ func ReadCombinedConfig(ctx context.Context, env map[string]string) (string, err
 		}
 	}
 
-	starlarkConfig, starlarkErr := EvaluateStarlarkConfig(ctx, ".cirrus.star", env)
+	starlarkConfig, starlarkErr := EvaluateStarlarkConfig(ctx, ".cirrus.star", env, lazyAffectedFiles)
 	if starlarkErr != nil && !os.IsNotExist(starlarkErr) {
 		return "", starlarkErr
 	}
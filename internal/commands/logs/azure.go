@@ -0,0 +1,16 @@
+package logs
+
+import (
+	"github.com/cirruslabs/echelon"
+	"github.com/cirruslabs/echelon/renderers"
+)
+
+// NewAzurePipelinesLogsRenderer creates a renderer that uses the Azure Pipelines logging commands
+// to put each scope in a collapsible group, which is the same syntax as GitHub Actions uses.
+func NewAzurePipelinesLogsRenderer(renderer *renderers.SimpleRenderer) echelon.LogRendered {
+	return &FoldableLogsRenderer{
+		delegate:          renderer,
+		startFoldTemplate: "##[group]%s",
+		endFoldTemplate:   "##[endgroup]",
+	}
+}
@@ -0,0 +1,26 @@
+package logs_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/logs"
+	"github.com/cirruslabs/echelon"
+	"github.com/cirruslabs/echelon/renderers"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestAzurePipelinesLogsRenderer ensures that each scope is put in an Azure Pipelines group.
+func TestAzurePipelinesLogsRenderer(t *testing.T) {
+	var buf bytes.Buffer
+
+	renderer := logs.NewAzurePipelinesLogsRenderer(renderers.NewSimpleRenderer(&buf, nil))
+	logger := echelon.NewLogger(echelon.InfoLevel, renderer)
+
+	taskLogger := logger.Scoped("'main' task")
+	taskLogger.Infof("Running...")
+	taskLogger.Finish(true)
+
+	output := buf.String()
+	assert.Contains(t, output, "##[group]'main' task")
+	assert.Contains(t, output, "##[endgroup]")
+}
@@ -0,0 +1,16 @@
+package logs
+
+import (
+	"github.com/cirruslabs/echelon"
+	"github.com/cirruslabs/echelon/renderers"
+)
+
+// NewBuildkiteLogsRenderer creates a renderer that starts a new collapsed group for each scope
+// and expands it when the scope fails, since Buildkite has no explicit end of the group.
+func NewBuildkiteLogsRenderer(renderer *renderers.SimpleRenderer) echelon.LogRendered {
+	return &FoldableLogsRenderer{
+		delegate:              renderer,
+		startFoldTemplate:     "--- %s",
+		failedEndFoldTemplate: "^^^ +++",
+	}
+}
@@ -0,0 +1,35 @@
+package logs_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/logs"
+	"github.com/cirruslabs/echelon"
+	"github.com/cirruslabs/echelon/renderers"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// TestBuildkiteLogsRenderer ensures that each scope starts a new Buildkite group,
+// which is expanded when the scope fails.
+func TestBuildkiteLogsRenderer(t *testing.T) {
+	var buf bytes.Buffer
+
+	renderer := logs.NewBuildkiteLogsRenderer(renderers.NewSimpleRenderer(&buf, nil))
+	logger := echelon.NewLogger(echelon.InfoLevel, renderer)
+
+	succeeding := logger.Scoped("'succeeding' task")
+	succeeding.Infof("Succeeding...")
+	succeeding.Finish(true)
+
+	output := buf.String()
+	assert.Contains(t, output, "--- 'succeeding' task")
+	assert.NotContains(t, output, "^^^ +++")
+
+	failing := logger.Scoped("'failing' task")
+	failing.Infof("Failing...")
+	failing.Finish(false)
+
+	output = buf.String()
+	assert.Contains(t, output, "--- 'failing' task")
+	assert.Contains(t, output, "^^^ +++")
+}
@@ -11,6 +11,12 @@ type FoldableLogsRenderer struct {
 	startFoldTemplate string
 	endFoldTemplate   string
 	escapeFunc        func(s string) string
+
+	// Used instead of the endFoldTemplate when the scope has failed, if specified
+	failedEndFoldTemplate string
+
+	// Provides the template arguments instead of the escaped last scope, if specified
+	templateArgsFunc func(scopes []string) []interface{}
 }
 
 func (r FoldableLogsRenderer) RenderScopeStarted(entry *echelon.LogScopeStarted) {
@@ -22,7 +28,12 @@ func (r FoldableLogsRenderer) RenderScopeStarted(entry *echelon.LogScopeStarted)
 
 func (r FoldableLogsRenderer) RenderScopeFinished(entry *echelon.LogScopeFinished) {
 	r.delegate.RenderScopeFinished(entry)
-	r.printFoldMessage(entry.GetScopes(), r.endFoldTemplate)
+
+	if !entry.Success() && r.failedEndFoldTemplate != "" {
+		r.printFoldMessage(entry.GetScopes(), r.failedEndFoldTemplate)
+	} else {
+		r.printFoldMessage(entry.GetScopes(), r.endFoldTemplate)
+	}
 }
 
 func (r FoldableLogsRenderer) RenderMessage(entry *echelon.LogEntryMessage) {
@@ -30,6 +41,11 @@ func (r FoldableLogsRenderer) RenderMessage(entry *echelon.LogEntryMessage) {
 }
 
 func (r FoldableLogsRenderer) printFoldMessage(scopes []string, template string) {
+	// Some CI systems have no explicit end of the fold
+	if template == "" {
+		return
+	}
+
 	if scopesCount := len(scopes); scopesCount > 0 {
 		lastScope := scopes[scopesCount-1]
 
@@ -37,7 +53,12 @@ func (r FoldableLogsRenderer) printFoldMessage(scopes []string, template string)
 			lastScope = r.escapeFunc(lastScope)
 		}
 
-		foldingMessage := echelon.NewLogEntryMessage(scopes, echelon.InfoLevel, template, lastScope)
+		templateArgs := []interface{}{lastScope}
+		if r.templateArgsFunc != nil {
+			templateArgs = r.templateArgsFunc(scopes)
+		}
+
+		foldingMessage := echelon.NewLogEntryMessage(scopes, echelon.InfoLevel, template, templateArgs...)
 		r.delegate.RenderMessage(foldingMessage)
 	}
 }
@@ -0,0 +1,36 @@
+package logs
+
+import (
+	"github.com/cirruslabs/echelon"
+	"github.com/cirruslabs/echelon/renderers"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var gitlabSectionNameForbiddenChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+func NewGitLabCILogsRenderer(renderer *renderers.SimpleRenderer) echelon.LogRendered {
+	return &FoldableLogsRenderer{
+		delegate:          renderer,
+		startFoldTemplate: "\x1b[0Ksection_start:%[1]d:%[2]s\r\x1b[0K%[3]s",
+		endFoldTemplate:   "\x1b[0Ksection_end:%[1]d:%[2]s\r\x1b[0K",
+		templateArgsFunc:  gitlabSectionArgs,
+	}
+}
+
+// gitlabSectionArgs provides the current timestamp, the section name that's unique
+// for each of the nested scopes and the section header.
+func gitlabSectionArgs(scopes []string) []interface{} {
+	var sectionNameParts []string
+
+	for _, scope := range scopes {
+		sectionNameParts = append(sectionNameParts, gitlabSectionNameForbiddenChars.ReplaceAllString(scope, "_"))
+	}
+
+	return []interface{}{
+		time.Now().Unix(),
+		strings.Join(sectionNameParts, "."),
+		scopes[len(scopes)-1],
+	}
+}
@@ -0,0 +1,31 @@
+package logs_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/logs"
+	"github.com/cirruslabs/echelon"
+	"github.com/cirruslabs/echelon/renderers"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+// TestGitLabCILogsRenderer ensures that each of the nested scopes gets its own GitLab CI section.
+func TestGitLabCILogsRenderer(t *testing.T) {
+	var buf bytes.Buffer
+
+	renderer := logs.NewGitLabCILogsRenderer(renderers.NewSimpleRenderer(&buf, nil))
+	logger := echelon.NewLogger(echelon.InfoLevel, renderer)
+
+	taskLogger := logger.Scoped("'main' task")
+	commandLogger := taskLogger.Scoped("'build' script")
+	commandLogger.Infof("Building...")
+	commandLogger.Finish(true)
+	taskLogger.Finish(true)
+
+	output := buf.String()
+	assert.Regexp(t, regexp.MustCompile(`section_start:\d+:_main_task\r\x1b\[0K'main' task`), output)
+	assert.Regexp(t, regexp.MustCompile(`section_start:\d+:_main_task._build_script\r\x1b\[0K'build' script`), output)
+	assert.Regexp(t, regexp.MustCompile(`section_end:\d+:_main_task._build_script\r`), output)
+	assert.Regexp(t, regexp.MustCompile(`section_end:\d+:_main_task\r`), output)
+}
@@ -0,0 +1,135 @@
+package logs
+
+import (
+	"encoding/json"
+	"github.com/cirruslabs/echelon"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	JSONEventScopeStarted  = "scope_started"
+	JSONEventScopeFinished = "scope_finished"
+	JSONEventLog           = "log"
+
+	JSONScopeBuild   = "build"
+	JSONScopeTask    = "task"
+	JSONScopeCommand = "command"
+
+	JSONStatusSucceeded = "succeeded"
+	JSONStatusFailed    = "failed"
+)
+
+// JSONEvent is a single line of the JSON output, suitable for consumption by other tools.
+type JSONEvent struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Scope   string    `json:"scope"`
+	Task    string    `json:"task,omitempty"`
+	Command string    `json:"command,omitempty"`
+	Status  string    `json:"status,omitempty"`
+	Message *string   `json:"message,omitempty"`
+}
+
+// JSONLogsRenderer emits a JSON object per line for each started and finished scope and for each log line.
+//
+// Top-level scopes are tasks and their nested scopes are commands, with the build being
+// the implicit scope that's started with the first event and finished by Finish().
+type JSONLogsRenderer struct {
+	encoder *json.Encoder
+	lock    sync.Mutex
+
+	buildStarted bool
+	buildFailed  bool
+}
+
+func NewJSONLogsRenderer(writer io.Writer) *JSONLogsRenderer {
+	return &JSONLogsRenderer{
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+func (r *JSONLogsRenderer) RenderScopeStarted(entry *echelon.LogScopeStarted) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.emit(newJSONEvent(JSONEventScopeStarted, entry.GetScopes()))
+}
+
+func (r *JSONLogsRenderer) RenderScopeFinished(entry *echelon.LogScopeFinished) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	event := newJSONEvent(JSONEventScopeFinished, entry.GetScopes())
+	event.Status = jsonStatus(entry.Success())
+
+	if event.Scope == JSONScopeTask && !entry.Success() {
+		r.buildFailed = true
+	}
+
+	r.emit(event)
+}
+
+func (r *JSONLogsRenderer) RenderMessage(entry *echelon.LogEntryMessage) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	event := newJSONEvent(JSONEventLog, entry.GetScopes())
+	message := entry.GetMessage()
+	event.Message = &message
+
+	r.emit(event)
+}
+
+// Finish emits the build finished event, which is only considered successful when none of the tasks failed.
+func (r *JSONLogsRenderer) Finish() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	event := newJSONEvent(JSONEventScopeFinished, nil)
+	event.Status = jsonStatus(!r.buildFailed)
+
+	r.emit(event)
+}
+
+func (r *JSONLogsRenderer) emit(event *JSONEvent) {
+	if !r.buildStarted {
+		r.buildStarted = true
+
+		buildEvent := newJSONEvent(JSONEventScopeStarted, nil)
+		buildEvent.Time = event.Time
+
+		_ = r.encoder.Encode(buildEvent)
+	}
+
+	_ = r.encoder.Encode(event)
+}
+
+func newJSONEvent(eventType string, scopes []string) *JSONEvent {
+	event := &JSONEvent{
+		Type:  eventType,
+		Time:  time.Now().UTC(),
+		Scope: JSONScopeBuild,
+	}
+
+	if len(scopes) >= 1 {
+		event.Scope = JSONScopeTask
+		event.Task = scopes[0]
+	}
+
+	if len(scopes) >= 2 {
+		event.Scope = JSONScopeCommand
+		event.Command = scopes[1]
+	}
+
+	return event
+}
+
+func jsonStatus(success bool) string {
+	if success {
+		return JSONStatusSucceeded
+	}
+
+	return JSONStatusFailed
+}
@@ -0,0 +1,72 @@
+package logs_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/logs"
+	"github.com/cirruslabs/echelon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func decodeJSONEvents(t *testing.T, buf *bytes.Buffer) (result []logs.JSONEvent) {
+	scanner := bufio.NewScanner(buf)
+
+	for scanner.Scan() {
+		var event logs.JSONEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.False(t, event.Time.IsZero())
+		result = append(result, event)
+	}
+
+	return result
+}
+
+// TestJSONLogsRenderer ensures that the JSON renderer attributes the events to the build, tasks and commands.
+func TestJSONLogsRenderer(t *testing.T) {
+	var buf bytes.Buffer
+
+	renderer := logs.NewJSONLogsRenderer(&buf)
+	logger := echelon.NewLogger(echelon.InfoLevel, renderer)
+
+	taskLogger := logger.Scoped("main")
+	commandLogger := taskLogger.Scoped("script")
+	commandLogger.Infof("Hello, World!")
+	commandLogger.Finish(true)
+	taskLogger.Finish(false)
+	renderer.Finish()
+
+	events := decodeJSONEvents(t, &buf)
+	require.Len(t, events, 7)
+
+	assert.Equal(t, logs.JSONEventScopeStarted, events[0].Type)
+	assert.Equal(t, logs.JSONScopeBuild, events[0].Scope)
+
+	assert.Equal(t, logs.JSONEventScopeStarted, events[1].Type)
+	assert.Equal(t, logs.JSONScopeTask, events[1].Scope)
+	assert.Equal(t, "main", events[1].Task)
+
+	assert.Equal(t, logs.JSONEventScopeStarted, events[2].Type)
+	assert.Equal(t, logs.JSONScopeCommand, events[2].Scope)
+	assert.Equal(t, "script", events[2].Command)
+
+	assert.Equal(t, logs.JSONEventLog, events[3].Type)
+	assert.Equal(t, "main", events[3].Task)
+	assert.Equal(t, "script", events[3].Command)
+	require.NotNil(t, events[3].Message)
+	assert.Equal(t, "Hello, World!", *events[3].Message)
+
+	assert.Equal(t, logs.JSONEventScopeFinished, events[4].Type)
+	assert.Equal(t, logs.JSONStatusSucceeded, events[4].Status)
+
+	assert.Equal(t, logs.JSONEventScopeFinished, events[5].Type)
+	assert.Equal(t, logs.JSONScopeTask, events[5].Scope)
+	assert.Equal(t, logs.JSONStatusFailed, events[5].Status)
+
+	// The build fails since one of its tasks has failed
+	assert.Equal(t, logs.JSONEventScopeFinished, events[6].Type)
+	assert.Equal(t, logs.JSONScopeBuild, events[6].Scope)
+	assert.Equal(t, logs.JSONStatusFailed, events[6].Status)
+}
@@ -16,6 +16,10 @@ const (
 	OutputTravis      = "travis"
 	OutputGA          = "github-actions"
 	OutputTeamCity    = "teamcity"
+	OutputGitLab      = "gitlab"
+	OutputBuildkite   = "buildkite"
+	OutputAzure       = "azure-pipelines"
+	OutputJSON        = "json"
 )
 
 func DefaultFormat() string {
@@ -31,6 +35,10 @@ func Formats() []string {
 		OutputTravis,
 		OutputGA,
 		OutputTeamCity,
+		OutputGitLab,
+		OutputBuildkite,
+		OutputAzure,
+		OutputJSON,
 	}
 }
 
@@ -44,6 +52,16 @@ func GetLogger(format string, verbose bool, logWriter io.Writer, logFile *os.Fil
 	if format == OutputAuto && envVariableIsSet("TEAMCITY_VERSION") {
 		format = OutputTeamCity
 	}
+	if format == OutputAuto && envVariableIsTrue("GITLAB_CI") {
+		format = OutputGitLab
+	}
+	if format == OutputAuto && envVariableIsTrue("BUILDKITE") {
+		format = OutputBuildkite
+	}
+	// Azure Pipelines sets TF_BUILD to "True"
+	if format == OutputAuto && envVariableIsSet("TF_BUILD") {
+		format = OutputAzure
+	}
 	if format == OutputAuto && envVariableIsTrue("CI") {
 		format = OutputSimple
 	}
@@ -77,6 +95,16 @@ func GetLogger(format string, verbose bool, logWriter io.Writer, logFile *os.Fil
 		renderer = NewGithubActionsLogsRenderer(defaultSimpleRenderer)
 	case OutputTeamCity:
 		renderer = NewTeamCityLogsRenderer(defaultSimpleRenderer)
+	case OutputGitLab:
+		renderer = NewGitLabCILogsRenderer(defaultSimpleRenderer)
+	case OutputBuildkite:
+		renderer = NewBuildkiteLogsRenderer(defaultSimpleRenderer)
+	case OutputAzure:
+		renderer = NewAzurePipelinesLogsRenderer(defaultSimpleRenderer)
+	case OutputJSON:
+		jsonRenderer := NewJSONLogsRenderer(logWriter)
+		cancelFunc = jsonRenderer.Finish
+		renderer = jsonRenderer
 	}
 
 	logger := echelon.NewLogger(echelon.InfoLevel, renderer)
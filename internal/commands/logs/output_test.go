@@ -0,0 +1,67 @@
+package logs_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/logs"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+// ciEnvironmentVariables are the variables used to detect the CI system the CLI is running in.
+var ciEnvironmentVariables = []string{
+	"TRAVIS", "GITHUB_ACTIONS", "TEAMCITY_VERSION", "GITLAB_CI", "BUILDKITE", "TF_BUILD", "CI",
+}
+
+// withEnvironment makes it look like only the specified CI environment variables are set.
+func withEnvironment(t *testing.T, env map[string]string) {
+	for _, name := range ciEnvironmentVariables {
+		oldValue, wasSet := os.LookupEnv(name)
+		name := name
+
+		t.Cleanup(func() {
+			if wasSet {
+				_ = os.Setenv(name, oldValue)
+			} else {
+				_ = os.Unsetenv(name)
+			}
+		})
+
+		if value, ok := env[name]; ok {
+			_ = os.Setenv(name, value)
+		} else {
+			_ = os.Unsetenv(name)
+		}
+	}
+}
+
+// TestGetLoggerAutoDetection ensures that the renderer is picked based on the CI system's environment variables.
+func TestGetLoggerAutoDetection(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Env      map[string]string
+		Expected string
+	}{
+		{"GitLab CI", map[string]string{"GITLAB_CI": "true", "CI": "true"}, "section_start:"},
+		{"Buildkite", map[string]string{"BUILDKITE": "true", "CI": "true"}, "--- 'main' task"},
+		{"Azure Pipelines", map[string]string{"TF_BUILD": "True"}, "##[group]'main' task"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			withEnvironment(t, testCase.Env)
+
+			var buf bytes.Buffer
+
+			logger, cancel := logs.GetLogger(logs.OutputAuto, false, &buf, os.Stdout)
+			taskLogger := logger.Scoped("'main' task")
+			taskLogger.Infof("Running...")
+			taskLogger.Finish(true)
+			cancel()
+
+			assert.Contains(t, buf.String(), testCase.Expected)
+		})
+	}
+}
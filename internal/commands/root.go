@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"github.com/cirruslabs/cirrus-cli/internal/commands/cache"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/graph"
 	"github.com/cirruslabs/cirrus-cli/internal/commands/helpers"
 	"github.com/cirruslabs/cirrus-cli/internal/commands/internal"
 	"github.com/cirruslabs/cirrus-cli/internal/commands/validate"
@@ -18,7 +20,9 @@ func NewRootCmd() *cobra.Command {
 
 	commands := []*cobra.Command{
 		validate.NewValidateCmd(),
+		graph.NewGraphCmd(),
 		newRunCmd(),
+		cache.NewCacheCmd(),
 		newServeCmd(),
 		internal.NewRootCmd(),
 		worker.NewRootCmd(),
@@ -3,18 +3,31 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/commands/cliconfig"
 	"github.com/cirruslabs/cirrus-cli/internal/commands/helpers"
 	"github.com/cirruslabs/cirrus-cli/internal/commands/logs"
 	"github.com/cirruslabs/cirrus-cli/internal/executor"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/artifacts"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	ecache "github.com/cirruslabs/cirrus-cli/internal/executor/cache"
 	eenvironment "github.com/cirruslabs/cirrus-cli/internal/executor/environment"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/options"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/plan"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/report"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/secrets"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/state"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/taskfilter"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/cirruslabs/cirrus-cli/pkg/parser"
 	"github.com/cirruslabs/cirrus-cli/pkg/parser/parsererror"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
+	"io"
 	"os"
 	"strings"
 )
@@ -25,7 +38,52 @@ var ErrRun = errors.New("run failed")
 var dirty bool
 var output string
 var environment []string
+var envFiles []string
 var verbose bool
+var parallel int
+var continueOnFailure bool
+var retries int
+var debugOnFailure bool
+var dryRun bool
+var baseRef string
+var event eenvironment.Event
+var gitRemote string
+
+// Task selection flags.
+var taskRegexes []string
+var taskLabels []string
+var skippedTasks []string
+var withDependencies bool
+
+// Flags for re-running the tasks from the previous build.
+var rerunFailed bool
+var resume bool
+
+// Reporting-related flags.
+var reportJSON string
+var reportJUnit string
+var logsDir string
+
+// Artifacts-related flags.
+var artifactsDir string
+
+// Cache-related flags.
+var cacheDir string
+var cacheShareWorktrees bool
+var cachePerBranch bool
+var cacheMaxSize string
+var remoteCache string
+var remoteCacheMode string
+
+// Secrets-related flags.
+var secretsFile string
+var secretsCommand string
+var secretsEnvPrefix string
+var secretEnv []string
+
+// Tracing-related flags.
+var traceFile string
+var traceFormat string
 
 // Container-related flags.
 var containerBackend string
@@ -43,28 +101,65 @@ func run(cmd *cobra.Command, args []string) error {
 	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
 	cmd.SilenceUsage = true
 
+	// Use the defaults from the CLI configuration files for the flags that weren't specified
+	cliConfig, err := cliconfig.Load(".")
+	if err != nil {
+		return err
+	}
+	applyCLIConfig(cmd, cliConfig)
+
+	if retries < 0 {
+		return fmt.Errorf("%w: --retries should be non-negative, got %d", ErrRun, retries)
+	}
+
 	backend, err := containerbackend.New(containerBackend)
 	if err != nil {
+		// Dry run doesn't need a container backend to show the plan
+		if !dryRun {
+			return err
+		}
+
+		backend = &containerbackend.Unimplemented{}
+	}
+
+	if err := event.Validate(); err != nil {
 		return err
 	}
 
 	projectDir := "."
+	projectEnvironment := eenvironment.ProjectSpecificWithRemote(projectDir, gitRemote)
 	baseEnvironment := eenvironment.Merge(
 		eenvironment.Static(),
 		eenvironment.BuildID(),
-		eenvironment.ProjectSpecific(projectDir),
+		event.Apply(projectEnvironment),
 	)
-	userSpecifiedEnvironment := helpers.EnvArgsToMap(environment)
+	userSpecifiedEnvironment, err := loadUserSpecifiedEnvironment(cliConfig)
+	if err != nil {
+		return err
+	}
+	resultingEnvironment := eenvironment.Merge(baseEnvironment, userSpecifiedEnvironment)
+
+	// Figure out which files were changed for the changesInclude() to work, but only if it's used
+	lazyAffectedFiles := helpers.NewLazyAffectedFiles(projectDir, baseRef, gitRemote, resultingEnvironment)
 
 	// Retrieve the combined YAML configuration
-	combinedYAML, err := helpers.ReadCombinedConfig(cmd.Context(),
-		eenvironment.Merge(baseEnvironment, userSpecifiedEnvironment))
+	combinedYAML, err := helpers.ReadCombinedConfig(cmd.Context(), resultingEnvironment, lazyAffectedFiles)
+	if err != nil {
+		return err
+	}
+
+	affectedFiles, err := lazyAffectedFiles.ForYAML(combinedYAML)
 	if err != nil {
 		return err
 	}
 
 	// Parse
-	p := parser.New(parser.WithEnvironment(userSpecifiedEnvironment), parser.WithMissingInstancesAllowed())
+	p := parser.New(
+		parser.WithEnvironment(eenvironment.Merge(event.Variables(projectEnvironment), userSpecifiedEnvironment)),
+		parser.WithAffectedFiles(affectedFiles),
+		parser.WithMissingInstancesAllowed(),
+		parser.WithAdditionalTaskProperties(build.AdditionalTaskProperties()),
+	)
 	result, err := p.Parse(cmd.Context(), combinedYAML)
 	if err != nil {
 		if re, ok := err.(*parsererror.Rich); ok {
@@ -76,15 +171,129 @@ func run(cmd *cobra.Command, args []string) error {
 
 	var executorOpts []executor.Option
 
-	// Enable logging
-	logger, cancel := logs.GetLogger(output, verbose, cmd.OutOrStdout(), os.Stdout)
+	// Enable logging, falling back to the simple output when the debug shell is requested
+	// since the interactive renderer would clash with the shell's output
+	logsFormat := output
+	if debugOnFailure && (logsFormat == logs.OutputAuto || logsFormat == logs.OutputInteractive ||
+		logsFormat == logs.OutputNoEmoji) {
+		logsFormat = logs.OutputSimple
+	}
+
+	logger, cancel := logs.GetLogger(logsFormat, verbose, cmd.OutOrStdout(), os.Stdout)
 	defer cancel()
 	executorOpts = append(executorOpts, executor.WithLogger(logger))
 
-	// Enable a task filter if the task name is specified
-	if len(args) == 1 {
-		taskFilter := taskfilter.MatchExactTask(args[0])
-		executorOpts = append(executorOpts, executor.WithTaskFilter(taskFilter))
+	var taskFilters []taskfilter.TaskFilter
+
+	// Only run the tasks that didn't succeed previously if asked to
+	buildState, taskFilter, err := loadBuildState(projectDir, combinedYAML, result.Tasks)
+	if err != nil {
+		return err
+	}
+	if taskFilter != nil {
+		taskFilters = append(taskFilters, taskFilter)
+	}
+
+	// Enable a task filter if the task selectors are specified
+	selector, err := newTaskSelector(args)
+	if err != nil {
+		return err
+	}
+	if selector != nil {
+		taskFilters = append(taskFilters, taskfilter.MatchSelector(*selector))
+	}
+
+	// Filter the tasks here instead of leaving it to the executor,
+	// so that only the secrets of the tasks that will run are resolved
+	tasks, err := taskfilter.Chain(taskFilters...)(result.Tasks)
+	if err != nil {
+		return err
+	}
+
+	// Decrypt the ENCRYPTED[...] variables using the locally available secrets
+	secretValues, err := resolveSecrets(tasks)
+	if err != nil {
+		return err
+	}
+
+	// Parallelism, running one task at a time when the debug shell is requested
+	// since the other tasks would otherwise keep logging into the shell's terminal
+	if debugOnFailure {
+		executorOpts = append(executorOpts, executor.WithParallelism(1))
+	} else {
+		executorOpts = append(executorOpts, executor.WithParallelism(parallel))
+	}
+
+	// Continue on failure mode
+	if continueOnFailure {
+		executorOpts = append(executorOpts, executor.WithContinueOnFailure())
+	}
+
+	// Debug shell for the failed tasks
+	if debugOnFailure {
+		executorOpts = append(executorOpts, executor.WithDebugOnFailure())
+	}
+
+	// Automatic retries
+	if retries != 0 {
+		executorOpts = append(executorOpts, executor.WithRetries(retries))
+	}
+
+	// Per-task and per-command log files
+	if logsDir != "" {
+		executorOpts = append(executorOpts, executor.WithTaskLogsDir(logsDir))
+	}
+
+	// Artifacts
+	resultingArtifactsDir := artifactsDir
+	if resultingArtifactsDir == "" {
+		resultingArtifactsDir, err = artifacts.DefaultDir(projectDir)
+		if err != nil {
+			return err
+		}
+	}
+	executorOpts = append(executorOpts, executor.WithArtifactsDir(resultingArtifactsDir))
+
+	// Cache location
+	if cacheDir != "" {
+		executorOpts = append(executorOpts, executor.WithCacheDir(cacheDir))
+	}
+	executorOpts = append(executorOpts, executor.WithCacheNamespaceOptions(ecache.NamespaceOptions{
+		ShareWorktrees: cacheShareWorktrees,
+		PerBranch:      cachePerBranch,
+		Remote:         gitRemote,
+	}))
+
+	// Cache size budget
+	resultingCacheMaxSize := cacheMaxSize
+	if resultingCacheMaxSize == "" {
+		resultingCacheMaxSize = os.Getenv("CIRRUS_CACHE_MAX_SIZE")
+	}
+	if resultingCacheMaxSize != "" {
+		cacheMaxSizeBytes, err := humanize.ParseBytes(resultingCacheMaxSize)
+		if err != nil {
+			return fmt.Errorf("%w: invalid cache size %q: %v", ErrRun, resultingCacheMaxSize, err)
+		}
+
+		executorOpts = append(executorOpts, executor.WithCacheMaxSize(cacheMaxSizeBytes))
+	}
+
+	// Remote cache
+	if remoteCache != "" {
+		remote, err := ecache.NewHTTP(remoteCache)
+		if err != nil {
+			return err
+		}
+
+		executorOpts = append(executorOpts, executor.WithRemoteCache(remote, remoteCacheMode))
+	}
+
+	// Secrets masking
+	if len(secretValues) != 0 {
+		executorOpts = append(executorOpts, executor.WithSecrets(secretValues))
+	}
+	if len(secretEnv) != 0 {
+		executorOpts = append(executorOpts, executor.WithSecretEnv(secretEnv))
 	}
 
 	// Dirty mode
@@ -110,21 +319,286 @@ func run(cmd *cobra.Command, args []string) error {
 	// Container backend
 	executorOpts = append(executorOpts, executor.WithContainerBackend(backend))
 
+	// Don't touch the caches when only showing what would be executed
+	if dryRun {
+		executorOpts = append(executorOpts, executor.WithDryRun())
+	}
+
+	// Tracing
+	var tracer *trace.Tracer
+	if traceFile != "" {
+		if err := trace.ValidateFormat(traceFormat); err != nil {
+			return err
+		}
+
+		tracer = trace.New()
+		executorOpts = append(executorOpts, executor.WithTracer(tracer))
+	}
+
 	// Run
-	e, err := executor.New(projectDir, result.Tasks, executorOpts...)
+	e, err := executor.New(projectDir, tasks, executorOpts...)
+	if err != nil {
+		return err
+	}
+
+	// Only show what would be executed if asked to
+	if dryRun {
+		return writePlan(cmd.Context(), cmd.OutOrStdout(), backend, e.Build(), e.Masker())
+	}
+
+	err = e.Run(cmd.Context())
+
+	// Remember the task statuses for the follow-up --rerun-failed and --resume invocations
+	buildState.Update(e.Build())
+	if saveErr := buildState.Save("", projectDir); saveErr != nil {
+		logger.Warnf("%v", saveErr)
+	}
+
+	// Write the trace even if the build has failed
+	if tracer != nil {
+		traceErr := writeReport(traceFile, func(w io.Writer) error {
+			return tracer.Write(w, traceFormat)
+		})
+		if traceErr != nil && err == nil {
+			err = traceErr
+		}
+	}
+
+	// Write reports even if the build has failed
+	if reportJSON != "" || reportJUnit != "" {
+		buildReport := report.New(e.Build())
+
+		if reportErr := writeReport(reportJSON, buildReport.WriteJSON); reportErr != nil && err == nil {
+			err = reportErr
+		}
+		if reportErr := writeReport(reportJUnit, buildReport.WriteJUnit); reportErr != nil && err == nil {
+			err = reportErr
+		}
+	}
+
+	return err
+}
+
+// newTaskSelector returns a selector built from the task names passed as arguments
+// and the task selection flags, or nil if no selection was requested.
+func newTaskSelector(args []string) (*taskfilter.Selector, error) {
+	if len(args) == 0 && len(taskRegexes) == 0 && len(taskLabels) == 0 && len(skippedTasks) == 0 {
+		return nil, nil
+	}
+
+	selector := &taskfilter.Selector{
+		WithDependencies: withDependencies,
+	}
+
+	for _, arg := range args {
+		matcher, err := taskfilter.NameMatcher(arg)
+		if err != nil {
+			return nil, err
+		}
+		selector.Include = append(selector.Include, matcher)
+	}
+
+	for _, taskRegex := range taskRegexes {
+		matcher, err := taskfilter.RegexMatcher(taskRegex)
+		if err != nil {
+			return nil, err
+		}
+		selector.Include = append(selector.Include, matcher)
+	}
+
+	for _, taskLabel := range taskLabels {
+		matcher, err := taskfilter.LabelMatcher(taskLabel)
+		if err != nil {
+			return nil, err
+		}
+		selector.Require = append(selector.Require, matcher)
+	}
+
+	for _, skippedTask := range skippedTasks {
+		matcher, err := taskfilter.NameMatcher(skippedTask)
+		if err != nil {
+			return nil, err
+		}
+		selector.Exclude = append(selector.Exclude, matcher)
+	}
+
+	return selector, nil
+}
+
+// applyCLIConfig overrides the flags that weren't specified explicitly with the values from the CLI configuration.
+func applyCLIConfig(cmd *cobra.Command, config *cliconfig.Config) {
+	flags := cmd.Flags()
+
+	if config.Output != "" && !flags.Changed("output") {
+		output = config.Output
+	}
+	if config.ContainerBackend != "" && !flags.Changed("container-backend") {
+		containerBackend = config.ContainerBackend
+	}
+	if config.ContainerLazyPull != nil && !flags.Changed("container-lazy-pull") {
+		containerLazyPull = *config.ContainerLazyPull
+	}
+	if config.DockerfileImageTemplate != "" && !flags.Changed("dockerfile-image-template") {
+		dockerfileImageTemplate = config.DockerfileImageTemplate
+	}
+	// $CIRRUS_CACHE_MAX_SIZE is more specific than the configuration files, just like the flag
+	if config.CacheMaxSize != "" && !flags.Changed("cache-max-size") && os.Getenv("CIRRUS_CACHE_MAX_SIZE") == "" {
+		cacheMaxSize = config.CacheMaxSize
+	}
+}
+
+// loadUserSpecifiedEnvironment merges the environment from the CLI configuration, the --env-file
+// flags (in order) and the -e flags, with the latter taking precedence.
+func loadUserSpecifiedEnvironment(config *cliconfig.Config) (map[string]string, error) {
+	envs := []map[string]string{config.Environment}
+
+	for _, envFile := range envFiles {
+		env, err := helpers.ReadEnvFile(envFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read environment file: %v", ErrRun, err)
+		}
+
+		envs = append(envs, env)
+	}
+
+	envs = append(envs, helpers.EnvArgsToMap(environment))
+
+	return eenvironment.Merge(envs...), nil
+}
+
+// resolveSecrets replaces the ENCRYPTED[...] variables in the tasks' environment with the values
+// from the configured secrets providers and returns these values.
+func resolveSecrets(tasks []*api.Task) ([]string, error) {
+	var providers []secrets.Provider
+
+	if secretsFile != "" {
+		file, err := secrets.NewFile(secretsFile)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, file)
+	}
+	if secretsEnvPrefix != "" {
+		providers = append(providers, secrets.NewEnvironment(secretsEnvPrefix))
+	}
+	if secretsCommand != "" {
+		providers = append(providers, secrets.NewCommand(secretsCommand))
+	}
+
+	if len(providers) == 0 {
+		return nil, nil
+	}
+
+	provider := secrets.Chain(providers...)
+
+	var secretValues []string
+
+	for _, task := range tasks {
+		taskSecretValues, err := secrets.Resolve(provider, task.Environment)
+		if err != nil {
+			return nil, fmt.Errorf("%w: task %s: %v", ErrRun, task.Name, err)
+		}
+		secretValues = append(secretValues, taskSecretValues...)
+	}
+
+	return secretValues, nil
+}
+
+// loadBuildState returns the state to be updated after this build and, when --rerun-failed
+// or --resume is used, a task filter that only keeps the tasks that didn't succeed previously.
+func loadBuildState(
+	projectDir string,
+	config string,
+	tasks []*api.Task,
+) (*state.State, taskfilter.TaskFilter, error) {
+	configHash := state.ConfigHash(config)
+
+	if !rerunFailed && !resume {
+		return state.New(configHash), nil, nil
+	}
+
+	if rerunFailed && resume {
+		return nil, nil, fmt.Errorf("%w: --rerun-failed and --resume are mutually exclusive", ErrRun)
+	}
+
+	previousState, err := state.Load("", projectDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := previousState.CheckConfigHash(configHash); err != nil {
+		return nil, nil, err
+	}
+
+	var ids []int64
+
+	if rerunFailed {
+		ids = previousState.FailedTaskIDs()
+	} else {
+		var allIDs []int64
+		for _, task := range tasks {
+			allIDs = append(allIDs, task.LocalGroupId)
+		}
+
+		ids = previousState.UnfinishedTaskIDs(allIDs)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil, fmt.Errorf("%w: nothing to re-run, all tasks have succeeded in the previous build", ErrRun)
+	}
+
+	return previousState, taskfilter.MatchTaskIDs(ids), nil
+}
+
+// writePlan writes the execution plan of the build, clamping the resources
+// the same way as when running the tasks if the container backend is available.
+func writePlan(
+	ctx context.Context,
+	w io.Writer,
+	backend containerbackend.ContainerBackend,
+	b *build.Build,
+	masker *secrets.Masker,
+) error {
+	info, err := backend.SystemInfo(ctx)
+	if err != nil {
+		info = nil
+	}
+
+	buildPlan := plan.New(b, info)
+	buildPlan.MaskSecrets(masker)
+
+	return buildPlan.WriteText(w)
+}
+
+func writeReport(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 
-	return e.Run(cmd.Context())
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
 }
 
 func newRunCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "run [flags] [task]",
+		Use:   "run [flags] [task...]",
 		Short: "Execute Cirrus CI tasks locally",
-		RunE:  run,
-		Args:  cobra.MaximumNArgs(1),
+		Long: "Execute Cirrus CI tasks locally.\n\n" +
+			"Defaults for the environment and some of the flags (output, container_backend, container_lazy_pull " +
+			"and dockerfile_image_template) can be set in ~/.config/cirrus/config.yml and overridden per project " +
+			"in " + cliconfig.ProjectFileName + ". Explicitly specified flags always take precedence, " +
+			"and the environment variables from --env-file and -e flags override the ones from the configuration.",
+		RunE: run,
+		Args: cobra.ArbitraryArgs,
 	}
 
 	// General flags
@@ -132,10 +606,99 @@ func newRunCmd() *cobra.Command {
 		"in read-write mode, otherwise the project directory files are copied, taking .gitignore into account")
 	cmd.PersistentFlags().StringArrayVarP(&environment, "environment", "e", []string{},
 		"set (-e A=B) or pass-through (-e A) an environment variable")
+	cmd.PersistentFlags().StringArrayVar(&envFiles, "env-file", []string{},
+		"read environment variables from the file in the dotenv syntax (-e flags take precedence)")
 	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "")
+	cmd.PersistentFlags().IntVar(&parallel, "parallel", 0, "maximum number of tasks to run in parallel "+
+		"(0 means auto-detect based on the CPU and memory available to the container engine)")
+	cmd.PersistentFlags().BoolVar(&continueOnFailure, "continue-on-failure", false,
+		"keep running the tasks that don't depend on the failed ones instead of aborting the whole build")
+	cmd.PersistentFlags().IntVar(&retries, "retries", 0,
+		"how many times to retry a task that has failed due to an infrastructure error (e.g. a failed image pull)")
+	cmd.PersistentFlags().BoolVar(&debugOnFailure, "debug-on-failure", false,
+		"attach an interactive shell to the container of the failed task to investigate the failure "+
+			"(implies --parallel 1)")
+	cmd.PersistentFlags().StringVar(&baseRef, "base-ref", "",
+		"branch or commit to compare against when evaluating changesInclude() (defaults to $CIRRUS_BASE_BRANCH)")
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false,
+		"print the tasks that would be executed and their settings without actually running them")
 	cmd.PersistentFlags().StringVarP(&output, "output", "o", logs.DefaultFormat(), fmt.Sprintf("output format of logs, "+
 		"supported values: %s", strings.Join(logs.Formats(), ", ")))
 
+	// Event emulation flags
+	helpers.AddEventFlags(cmd, &event)
+
+	// Repository detection flags
+	helpers.AddGitRemoteFlag(cmd, &gitRemote)
+
+	// Task selection flags
+	cmd.PersistentFlags().StringArrayVar(&taskRegexes, "task-regex", []string{},
+		"only run the tasks whose names match the regular expression")
+	cmd.PersistentFlags().StringArrayVar(&taskLabels, "label", []string{},
+		"only run the tasks that have a label matching the pattern (e.g. --label VERSION:1.15)")
+	cmd.PersistentFlags().StringArrayVar(&skippedTasks, "skip-task", []string{},
+		"don't run the tasks matching the name or the pattern")
+	cmd.PersistentFlags().BoolVar(&withDependencies, "with-dependencies", false,
+		"also run the dependencies of the selected tasks instead of considering them satisfied")
+
+	// Flags for re-running the tasks from the previous build
+	cmd.PersistentFlags().BoolVar(&rerunFailed, "rerun-failed", false,
+		"only run the tasks that have failed in the previous build and the tasks that didn't run because of that")
+	cmd.PersistentFlags().BoolVar(&resume, "resume", false,
+		"only run the tasks that have failed or didn't run in the previous build")
+
+	// Reporting-related flags
+	cmd.PersistentFlags().StringVar(&reportJSON, "report-json", "",
+		"write a JSON report with the status and duration of each task and command to the specified path")
+	cmd.PersistentFlags().StringVar(&reportJUnit, "report-junit", "",
+		"write a JUnit XML report with the status and duration of each task and command to the specified path")
+	cmd.PersistentFlags().StringVar(&logsDir, "logs-dir", "",
+		"write the logs of each task and each of its commands to the separate files in the specified directory")
+
+	// Artifacts-related flags
+	cmd.PersistentFlags().StringVar(&artifactsDir, "artifacts-dir", "",
+		"directory to store the artifacts uploaded by the tasks in "+
+			"(defaults to a per-project directory in the user's cache directory)")
+
+	// Cache-related flags
+	cmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "",
+		"directory to store the local cache in (defaults to the user's cache directory)")
+	cmd.PersistentFlags().BoolVar(&cacheShareWorktrees, "cache-share-worktrees", false,
+		"share the local cache between all of the worktrees of the project's Git repository")
+	cmd.PersistentFlags().BoolVar(&cachePerBranch, "cache-per-branch", false,
+		"use a separate local cache for each branch of the project's Git repository")
+	cmd.PersistentFlags().StringVar(&cacheMaxSize, "cache-max-size", "",
+		"evict the least recently used cache entries once the project's cache exceeds the specified size "+
+			"(e.g. 10GB, defaults to $CIRRUS_CACHE_MAX_SIZE or the cache_max_size from the CLI configuration)")
+	cmd.PersistentFlags().StringVar(&remoteCache, "remote-cache", "",
+		"URL of the HTTP server (e.g. bazel-remote or an S3-compatible storage) to share the cache entries with, "+
+			"blobs are accessed with GET, HEAD and PUT requests to <URL>/<key>")
+	cmd.PersistentFlags().StringVar(&remoteCacheMode, "remote-cache-mode", ecache.RemoteModeReadThrough,
+		fmt.Sprintf("how to use the remote cache, supported values: %s", strings.Join(ecache.RemoteModes(), ", ")))
+
+	// Secrets-related flags
+	cmd.PersistentFlags().StringVar(&secretsFile, "secrets-file", "",
+		"YAML or JSON file that maps the ENCRYPTED[...] ciphertexts or variable names to their decrypted values")
+	cmd.PersistentFlags().StringVar(&secretsCommand, "secrets-command", "",
+		fmt.Sprintf("shell command that prints the decrypted value of the ENCRYPTED[...] variable "+
+			"(e.g. \"pass show ci/$%s\") or exits with code 2 if the secret is unknown, "+
+			"the variable's ciphertext is passed in $%s", secrets.CommandNameEnvVariable,
+			secrets.CommandCiphertextEnvVariable))
+	cmd.PersistentFlags().StringVar(&secretsEnvPrefix, "secrets-env-prefix", "",
+		"decrypt the ENCRYPTED[...] variables using the values of the CLI's environment variables "+
+			"named after them with the specified prefix (e.g. CIRRUS_SECRET_)")
+	cmd.PersistentFlags().StringArrayVar(&secretEnv, "secret-env", []string{},
+		"mask the value of the specified environment variable in the task logs "+
+			"unless it's shorter than 4 characters (values of the *_TOKEN and *_PASSWORD variables "+
+			"are masked under the same condition and the decrypted variables are always masked)")
+
+	// Tracing-related flags
+	cmd.PersistentFlags().StringVar(&traceFile, "trace-file", "",
+		"record the timeline of the build (image pulls, volume creation, commands, caching, etc.) "+
+			"to the specified path")
+	cmd.PersistentFlags().StringVar(&traceFormat, "trace-format", trace.FormatChrome,
+		fmt.Sprintf("format of the trace file, supported values: %s", strings.Join(trace.Formats(), ", ")))
+
 	// Container-related flags
 	cmd.PersistentFlags().StringVar(&containerBackend, "container-backend", containerbackend.BackendAuto,
 		fmt.Sprintf("container engine backend to use, either \"%s\", \"%s\" or \"%s\"",
@@ -5,6 +5,7 @@ package commands_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/cirruslabs/cirrus-cli/internal/commands"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
@@ -96,6 +97,157 @@ func TestRunTaskDependencyRemoval(t *testing.T) {
 	require.Nil(t, err)
 }
 
+// TestRunTaskWithDependencies ensures that the dependencies of the selected
+// task are also run when --with-dependencies is used.
+func TestRunTaskWithDependencies(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/run-task-dependency-removal")
+
+	// Create os.Stderr writer that duplicates it's output to buf
+	buf := bytes.NewBufferString("")
+	writer := io.MultiWriter(os.Stderr, buf)
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--container-lazy-pull", "-v", "-o simple", "--with-dependencies", "ba*"})
+	command.SetOut(writer)
+	command.SetErr(writer)
+	err := command.Execute()
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "task foo (0) succeeded")
+	assert.Contains(t, buf.String(), "task bar (1) succeeded")
+}
+
+// TestRunContinueOnFailure ensures that the tasks unrelated to the failed one
+// are still run and that the dependents of the failed task are skipped.
+func TestRunContinueOnFailure(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/run-continue-on-failure")
+
+	// Create os.Stderr writer that duplicates it's output to buf
+	buf := bytes.NewBufferString("")
+	writer := io.MultiWriter(os.Stderr, buf)
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--container-lazy-pull", "-v", "-o simple", "--continue-on-failure"})
+	command.SetOut(writer)
+	command.SetErr(writer)
+	err := command.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "task independent (2) succeeded")
+	assert.Contains(t, buf.String(), "task dependent (3) skipped")
+	assert.Contains(t, buf.String(), "ignoring failure of task allowed_to_fail (1)")
+}
+
+// TestRunRerunFailed ensures that --rerun-failed re-runs both the failed task
+// and its dependents that didn't get to run because of the failure.
+func TestRunRerunFailed(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/run-rerun-failed")
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--container-lazy-pull", "-v", "-o simple"})
+	err := command.Execute()
+	require.Error(t, err)
+
+	// Create os.Stderr writer that duplicates it's output to buf
+	buf := bytes.NewBufferString("")
+	writer := io.MultiWriter(os.Stderr, buf)
+
+	command = commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--container-lazy-pull", "-v", "-o simple", "--rerun-failed", "-e", "FIXED=true"})
+	command.SetOut(writer)
+	command.SetErr(writer)
+	err = command.Execute()
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "task failing (0) succeeded")
+	assert.Contains(t, buf.String(), "task dependent (1) succeeded")
+}
+
+// TestRunEventEmulation ensures that the emulated event is visible both
+// to the configuration parser and to the tasks.
+func TestRunEventEmulation(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/run-event-emulation")
+
+	// Create os.Stderr writer that duplicates it's output to buf
+	buf := bytes.NewBufferString("")
+	writer := io.MultiWriter(os.Stderr, buf)
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--container-lazy-pull", "-v", "-o simple",
+		"--pr", "42", "--base-branch", "main", "--pr-label", "bug", "--pr-label", "ci"})
+	command.SetOut(writer)
+	command.SetErr(writer)
+	err := command.Execute()
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "task pull_request")
+	assert.NotContains(t, buf.String(), "task release")
+}
+
+// TestRunDryRun ensures that the execution plan is printed instead of running the tasks
+// and that nothing is changed on the disk.
+func TestRunDryRun(t *testing.T) {
+	testutil.TempChdir(t)
+
+	if err := ioutil.WriteFile(".cirrus.yml", validConfig, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.NewBufferString("")
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "-v", "-o simple", "--dry-run", "--cache-dir", "cache"})
+	command.SetOut(buf)
+	command.SetErr(buf)
+	err := command.Execute()
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Execution plan (1 task(s))")
+	assert.Contains(t, buf.String(), "image: debian:latest")
+	assert.NotContains(t, buf.String(), "succeeded")
+	assert.NoDirExists(t, "cache")
+}
+
+// TestRunSecretsOnlyForSelectedTasks ensures that the secrets are only resolved for the tasks that will run.
+func TestRunSecretsOnlyForSelectedTasks(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/run-secrets-filtering")
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "-v", "-o simple", "--dry-run",
+		"--secrets-command", "touch decrypted && echo value", "main"})
+	err := command.Execute()
+
+	require.NoError(t, err)
+	assert.NoFileExists(t, "decrypted")
+}
+
+// TestRunReport ensures that the JSON and JUnit reports are written after the build.
+func TestRunReport(t *testing.T) {
+	testutil.TempChdir(t)
+
+	if err := ioutil.WriteFile(".cirrus.yml", validConfig, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--container-lazy-pull", "-v", "-o simple",
+		"--report-json", "report.json", "--report-junit", "report.xml"})
+	err := command.Execute()
+	require.NoError(t, err)
+
+	jsonReport, err := ioutil.ReadFile("report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(jsonReport), `"status": "succeeded"`)
+
+	junitReport, err := ioutil.ReadFile("report.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(junitReport), `failures="0"`)
+}
+
 // TestRunEnvironmentSet ensures that the user can set environment variables.
 func TestRunEnvironmentSet(t *testing.T) {
 	testutil.TempChdirPopulatedWith(t, "testdata/run-environment")
@@ -136,6 +288,30 @@ func TestRunEnvironmentPrecedence(t *testing.T) {
 	require.Nil(t, err)
 }
 
+// TestRunEnvironmentFile ensures that the user can set environment variables using the dotenv files.
+func TestRunEnvironmentFile(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/run-environment-file")
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--container-lazy-pull", "-v", "-o simple",
+		"--env-file", ".env", "--env-file", ".env.local"})
+	err := command.Execute()
+
+	require.Nil(t, err)
+}
+
+// TestRunEnvironmentLocalConfig ensures that the environment and flag defaults
+// are picked up from the project-level CLI configuration.
+func TestRunEnvironmentLocalConfig(t *testing.T) {
+	testutil.TempChdirPopulatedWith(t, "testdata/run-environment-local-config")
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "-v", "-o simple"})
+	err := command.Execute()
+
+	require.Nil(t, err)
+}
+
 // TestRunEnvironmentOnlyIf ensures that user-specified environment variables
 // are propagated to the configuration parser.
 func TestRunEnvironmentOnlyIf(t *testing.T) {
@@ -340,3 +516,18 @@ func TestRunPrebuiltImageTemplate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestRunNegativeRetries ensures that the negative --retries values are rejected.
+func TestRunNegativeRetries(t *testing.T) {
+	testutil.TempChdir(t)
+
+	if err := ioutil.WriteFile(".cirrus.yml", validConfig, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	command := commands.NewRootCmd()
+	command.SetArgs([]string{"run", "--retries", "-1"})
+	err := command.Execute()
+
+	assert.True(t, errors.Is(err, commands.ErrRun))
+}
@@ -19,6 +19,9 @@ var ErrValidate = errors.New("validate failed")
 // General flags.
 var validateFile string
 var environment []string
+var baseRef string
+var event eenvironment.Event
+var gitRemote string
 
 func additionalInstancesOption(stderr io.Writer) parser.Option {
 	// Try to retrieve additional instances from the Cirrus Cloud
@@ -45,22 +48,30 @@ func validate(cmd *cobra.Command, args []string) error {
 	// https://github.com/spf13/cobra/issues/340#issuecomment-374617413
 	cmd.SilenceUsage = true
 
+	if err := event.Validate(); err != nil {
+		return err
+	}
+
 	// Craft the environment
+	projectEnvironment := eenvironment.ProjectSpecificWithRemote(".", gitRemote)
 	baseEnvironment := eenvironment.Merge(
 		eenvironment.Static(),
 		eenvironment.BuildID(),
-		eenvironment.ProjectSpecific("."),
+		event.Apply(projectEnvironment),
 	)
 	userSpecifiedEnvironment := helpers.EnvArgsToMap(environment)
 	resultingEnvironment := eenvironment.Merge(baseEnvironment, userSpecifiedEnvironment)
 
+	// Figure out which files were changed for the changesInclude() to work, but only if it's used
+	lazyAffectedFiles := helpers.NewLazyAffectedFiles(".", baseRef, gitRemote, resultingEnvironment)
+
 	// Retrieve a combined YAML configuration or a specific one if asked to
 	var configuration string
 	var err error
 
 	switch {
 	case validateFile == "":
-		configuration, err = helpers.ReadCombinedConfig(cmd.Context(), resultingEnvironment)
+		configuration, err = helpers.ReadCombinedConfig(cmd.Context(), resultingEnvironment, lazyAffectedFiles)
 		if err != nil {
 			return err
 		}
@@ -70,7 +81,8 @@ func validate(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	case strings.HasSuffix(validateFile, ".star"):
-		configuration, err = helpers.EvaluateStarlarkConfig(cmd.Context(), validateFile, resultingEnvironment)
+		configuration, err = helpers.EvaluateStarlarkConfig(cmd.Context(), validateFile, resultingEnvironment,
+			lazyAffectedFiles)
 		if err != nil {
 			return err
 		}
@@ -78,8 +90,17 @@ func validate(cmd *cobra.Command, args []string) error {
 		return ErrValidate
 	}
 
+	affectedFiles, err := lazyAffectedFiles.ForYAML(configuration)
+	if err != nil {
+		return err
+	}
+
 	// Parse
-	p := parser.New(parser.WithEnvironment(userSpecifiedEnvironment), additionalInstancesOption(cmd.ErrOrStderr()))
+	p := parser.New(
+		parser.WithEnvironment(eenvironment.Merge(event.Variables(projectEnvironment), userSpecifiedEnvironment)),
+		parser.WithAffectedFiles(affectedFiles),
+		additionalInstancesOption(cmd.ErrOrStderr()),
+	)
 	_, err = p.Parse(cmd.Context(), configuration)
 	if err != nil {
 		if re, ok := err.(*parsererror.Rich); ok {
@@ -104,6 +125,14 @@ func NewValidateCmd() *cobra.Command {
 		"set (-e A=B) or pass-through (-e A) an environment variable to the Starlark interpreter")
 	cmd.PersistentFlags().StringVarP(&validateFile, "file", "f", "",
 		"use file as the configuration file (the path should end with either .yml or ..star)")
+	cmd.PersistentFlags().StringVar(&baseRef, "base-ref", "",
+		"branch or commit to compare against when evaluating changesInclude() (defaults to $CIRRUS_BASE_BRANCH)")
+
+	// Event emulation flags
+	helpers.AddEventFlags(cmd, &event)
+
+	// Repository detection flags
+	helpers.AddGitRemoteFlag(cmd, &gitRemote)
 
 	return cmd
 }
@@ -0,0 +1,115 @@
+package artifacts
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MetadataSuffix is appended to the artifact's name to form the name of the file
+// that holds the artifact's metadata, which is stored next to the artifact's directory.
+const MetadataSuffix = ".metadata.json"
+
+var (
+	ErrInvalidPath = errors.New("invalid artifact path")
+	ErrInternal    = errors.New("internal artifacts storage error")
+)
+
+// Store keeps the artifacts uploaded by the tasks in a
+// <dir>/<task unique name>/<artifact name>/<relative path> hierarchy.
+//
+// Unique names are used instead of the plain task names because
+// the tasks generated by a matrix all have the same name.
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns the directory where the artifacts of the project's tasks are stored unless
+// configured otherwise, which resides in the user's cache directory (and not in the project directory)
+// to avoid copying the artifacts into the containers of the subsequently run tasks.
+func DefaultDir(projectDir string) (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	absoluteProjectDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	projectHash := sha256.Sum256([]byte(absoluteProjectDir))
+
+	return filepath.Join(userCacheDir, "cirrus", "artifacts", fmt.Sprintf("%x", projectHash)), nil
+}
+
+func New(dir string) *Store {
+	return &Store{
+		dir: dir,
+	}
+}
+
+func (store *Store) Dir() string {
+	return store.dir
+}
+
+// TaskDirName returns the name of the Dir()'s subdirectory that holds the task's artifacts.
+func (store *Store) TaskDirName(taskUniqueName string) string {
+	return sanitizeName(taskUniqueName)
+}
+
+// Clear removes the artifacts left by the previous runs of the task.
+func (store *Store) Clear(taskUniqueName string) error {
+	if err := os.RemoveAll(filepath.Join(store.dir, store.TaskDirName(taskUniqueName))); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Upload starts the upload of the task's artifact, removing the files that
+// may have been left by the previous upload of the artifact with the same name.
+func (store *Store) Upload(taskUniqueName, name, artifactType, format string) (*Upload, error) {
+	taskDir := filepath.Join(store.dir, store.TaskDirName(taskUniqueName))
+	artifactDir := filepath.Join(taskDir, sanitizeName(name))
+
+	if err := os.RemoveAll(artifactDir); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if err := os.MkdirAll(artifactDir, 0700); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return &Upload{
+		dir:          artifactDir,
+		metadataPath: filepath.Join(taskDir, sanitizeName(name)+MetadataSuffix),
+		metadata: Metadata{
+			Name:   name,
+			Type:   artifactType,
+			Format: format,
+		},
+		seenPaths: make(map[string]struct{}),
+	}, nil
+}
+
+// sanitizeName makes sure that the task and artifact names can be safely used as a single path component,
+// including on Windows, since the task unique names include the task's labels.
+func sanitizeName(name string) string {
+	name = strings.Map(func(c rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, c) {
+			return '_'
+		}
+
+		return c
+	}, name)
+
+	if name == "" || name == "." || name == ".." {
+		name = strings.Repeat("_", len(name)+1)
+	}
+
+	return name
+}
@@ -0,0 +1,82 @@
+package artifacts_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/artifacts"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestUpload ensures that the uploaded files preserve their relative paths
+// and the artifact's metadata is recorded next to them.
+func TestUpload(t *testing.T) {
+	dir := testutil.TempDir(t)
+	store := artifacts.New(dir)
+
+	upload, err := store.Upload("main", "binaries", "application/octet-stream", "")
+	require.NoError(t, err)
+
+	require.NoError(t, upload.Write("build/app", []byte("first ")))
+	require.NoError(t, upload.Write("build/app", []byte("chunk")))
+	require.NoError(t, upload.Write("README.md", []byte("readme")))
+	require.NoError(t, upload.Write("build/app", []byte(" and more")))
+	require.NoError(t, upload.Finalize())
+
+	app, err := ioutil.ReadFile(filepath.Join(dir, "main", "binaries", "build", "app"))
+	require.NoError(t, err)
+	assert.Equal(t, "first chunk and more", string(app))
+
+	readme, err := ioutil.ReadFile(filepath.Join(dir, "main", "binaries", "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "readme", string(readme))
+
+	metadataBytes, err := ioutil.ReadFile(filepath.Join(dir, "main", "binaries"+artifacts.MetadataSuffix))
+	require.NoError(t, err)
+
+	var metadata artifacts.Metadata
+	require.NoError(t, json.Unmarshal(metadataBytes, &metadata))
+	assert.Equal(t, artifacts.Metadata{
+		Name:  "binaries",
+		Type:  "application/octet-stream",
+		Files: []string{"README.md", "build/app"},
+	}, metadata)
+}
+
+// TestUploadPathEscape ensures that the uploaded files cannot escape the artifact's directory.
+func TestUploadPathEscape(t *testing.T) {
+	dir := testutil.TempDir(t)
+	store := artifacts.New(dir)
+
+	upload, err := store.Upload("../task", "..", "", "")
+	require.NoError(t, err)
+
+	require.NoError(t, upload.Write("../../../escaped.txt", []byte("contents")))
+	require.NoError(t, upload.Write("/etc/passwd", []byte("contents")))
+	require.NoError(t, upload.Finalize())
+
+	assert.FileExists(t, filepath.Join(dir, ".._task", "___", "escaped.txt"))
+	assert.FileExists(t, filepath.Join(dir, ".._task", "___", "etc", "passwd"))
+
+	err = upload.Write("..", []byte("contents"))
+	assert.True(t, errors.Is(err, artifacts.ErrInvalidPath))
+}
+
+// TestDefaultDir ensures that by default the artifacts are stored outside of the project directory
+// and that each project gets its own directory.
+func TestDefaultDir(t *testing.T) {
+	projectDir := testutil.TempDir(t)
+
+	dir, err := artifacts.DefaultDir(projectDir)
+	require.NoError(t, err)
+	assert.False(t, strings.HasPrefix(dir, projectDir))
+
+	otherDir, err := artifacts.DefaultDir(testutil.TempDir(t))
+	require.NoError(t, err)
+	assert.NotEqual(t, dir, otherDir)
+}
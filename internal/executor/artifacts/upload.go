@@ -0,0 +1,130 @@
+package artifacts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Metadata describes the uploaded artifact.
+type Metadata struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type,omitempty"`
+	Format string   `json:"format,omitempty"`
+	Files  []string `json:"files"`
+}
+
+type Upload struct {
+	dir          string
+	metadataPath string
+	metadata     Metadata
+
+	currentPath string
+	currentFile *os.File
+	seenPaths   map[string]struct{}
+}
+
+// Write appends the data to the file at the artifactPath, which is relative
+// to the task's working directory.
+func (upload *Upload) Write(artifactPath string, data []byte) error {
+	if upload.currentFile == nil || artifactPath != upload.currentPath {
+		if err := upload.closeCurrent(); err != nil {
+			return err
+		}
+
+		if err := upload.open(artifactPath); err != nil {
+			return err
+		}
+	}
+
+	if _, err := upload.currentFile.Write(data); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Finalize finishes the upload and records the artifact's metadata.
+func (upload *Upload) Finalize() error {
+	if err := upload.closeCurrent(); err != nil {
+		return err
+	}
+
+	sort.Strings(upload.metadata.Files)
+
+	metadataBytes, err := json.MarshalIndent(&upload.metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if err := ioutil.WriteFile(upload.metadataPath, metadataBytes, 0600); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (upload *Upload) open(artifactPath string) error {
+	relativePath, err := normalizePath(artifactPath)
+	if err != nil {
+		return err
+	}
+
+	// The same file might be sent in multiple non-consecutive series of chunks
+	flags := os.O_WRONLY | os.O_APPEND
+	if _, seen := upload.seenPaths[relativePath]; !seen {
+		upload.seenPaths[relativePath] = struct{}{}
+		upload.metadata.Files = append(upload.metadata.Files, relativePath)
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	fullPath := filepath.Join(upload.dir, filepath.FromSlash(relativePath))
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0700); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	file, err := os.OpenFile(fullPath, flags, 0600)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	upload.currentPath = artifactPath
+	upload.currentFile = file
+
+	return nil
+}
+
+func (upload *Upload) closeCurrent() error {
+	if upload.currentFile == nil {
+		return nil
+	}
+
+	err := upload.currentFile.Close()
+	upload.currentFile = nil
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// normalizePath converts the path reported by the agent into a slash-separated
+// relative path that is guaranteed to not escape the artifact's directory.
+func normalizePath(artifactPath string) (string, error) {
+	slashPath := strings.ReplaceAll(artifactPath, "\\", "/")
+
+	// Cleaning the rooted path eliminates all of the ".." elements
+	relativePath := strings.TrimPrefix(path.Clean("/"+slashPath), "/")
+	if relativePath == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPath, artifactPath)
+	}
+
+	return relativePath, nil
+}
@@ -8,19 +8,25 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"path/filepath"
+	"sort"
 )
 
 type Build struct {
 	// A directory on host where .cirrus.yml that drives this execution is located
 	ProjectDir string
 
-	Cache *cache.Cache
+	Cache cache.Cache
 
 	// The actual tasks comprising this build
 	tasks map[int64]*Task
 }
 
-func New(projectDir string, tasks []*api.Task, logger logger.Lightweight) (*Build, error) {
+func New(
+	projectDir string,
+	tasks []*api.Task,
+	logger logger.Lightweight,
+	opts ...Option,
+) (*Build, error) {
 	// Normalize project directory path on host as it might be
 	// simply ".", which is not suitable for bind mounting it
 	// later to the container
@@ -39,16 +45,26 @@ func New(projectDir string, tasks []*api.Task, logger logger.Lightweight) (*Buil
 		wrappedTasks[wrappedTask.ID] = wrappedTask
 	}
 
-	c, err := cache.New("", filepath.Base(absoluteProjectDir))
-	if err != nil {
-		return nil, err
-	}
-
-	return &Build{
+	b := &Build{
 		ProjectDir: absoluteProjectDir,
-		Cache:      c,
 		tasks:      wrappedTasks,
-	}, nil
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	// Apply default options (to cover those that weren't specified)
+	if b.Cache == nil {
+		c, err := cache.New("", cache.Namespace(absoluteProjectDir, cache.NamespaceOptions{}))
+		if err != nil {
+			return nil, err
+		}
+		b.Cache = c
+	}
+
+	return b, nil
 }
 
 func (b *Build) Tasks() (result []*Task) {
@@ -93,16 +109,49 @@ func (b *Build) taskHasUnresolvedDependencies(task *Task) bool {
 	return false
 }
 
-func (b *Build) GetNextTask() (result *Task) {
+// HasFailedDependencies returns true if any of the task's dependencies has finished
+// without succeeding, unless that dependency is allowed to fail.
+func (b *Build) HasFailedDependencies(task *Task) bool {
+	for _, requiredGroup := range task.RequiredIDs {
+		requiredTask := b.GetTask(requiredGroup)
+
+		switch requiredTask.Status() {
+		case taskstatus.New, taskstatus.Succeeded:
+			continue
+		case taskstatus.Failed, taskstatus.TimedOut:
+			if requiredTask.AllowFailures {
+				continue
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// GetReadyTasks returns all undone tasks whose dependencies are already resolved, ordered by their IDs.
+func (b *Build) GetReadyTasks() (result []*Task) {
 	for _, task := range b.tasks {
 		if task.Status() != taskstatus.New || b.taskHasUnresolvedDependencies(task) {
 			continue
 		}
 
-		if result == nil || task.ID < result.ID {
-			result = task
-		}
+		result = append(result, task)
 	}
 
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
 	return
 }
+
+func (b *Build) GetNextTask() *Task {
+	readyTasks := b.GetReadyTasks()
+	if len(readyTasks) == 0 {
+		return nil
+	}
+
+	return readyTasks[0]
+}
@@ -3,6 +3,7 @@ package build_test
 import (
 	"github.com/cirruslabs/cirrus-ci-agent/api"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/taskstatus"
 	"github.com/cirruslabs/cirrus-cli/internal/testutil"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -61,3 +62,83 @@ func TestNoUnresolvedDeps(t *testing.T) {
 
 	assert.Nil(t, b.GetNextTask())
 }
+
+// TestReadyTasks ensures that all of the tasks with resolved dependencies are returned at once.
+func TestReadyTasks(t *testing.T) {
+	projectDir := testutil.TempDir(t)
+
+	// Tasks without commands are considered succeeded, so add one to each task
+	commands := []*api.Command{
+		{
+			Name: "main",
+			Instruction: &api.Command_ScriptInstruction{
+				ScriptInstruction: &api.ScriptInstruction{Scripts: []string{"true"}},
+			},
+		},
+	}
+
+	b, err := build.New(projectDir, []*api.Task{
+		{
+			LocalGroupId: 0,
+			Commands:     commands,
+			Instance:     testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId: 1,
+			Commands:     commands,
+			Instance:     testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId:   2,
+			RequiredGroups: []int64{0, 1},
+			Commands:       commands,
+			Instance:       testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var readyIDs []int64
+	for _, task := range b.GetReadyTasks() {
+		readyIDs = append(readyIDs, task.ID)
+	}
+
+	assert.Equal(t, []int64{0, 1}, readyIDs)
+}
+
+// TestFailedDependencies ensures that only the failed dependencies that are not allowed to fail are taken into account.
+func TestFailedDependencies(t *testing.T) {
+	projectDir := testutil.TempDir(t)
+
+	b, err := build.New(projectDir, []*api.Task{
+		{
+			LocalGroupId: 0,
+			Instance:     testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId: 1,
+			Instance:     testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId:   2,
+			RequiredGroups: []int64{0},
+			Instance:       testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId:   3,
+			RequiredGroups: []int64{1},
+			Instance:       testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.GetTask(0).SetStatus(taskstatus.Failed)
+	b.GetTask(1).SetStatus(taskstatus.Failed)
+	b.GetTask(1).AllowFailures = true
+
+	assert.True(t, b.HasFailedDependencies(b.GetTask(2)))
+	assert.False(t, b.HasFailedDependencies(b.GetTask(3)))
+}
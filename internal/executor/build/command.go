@@ -4,10 +4,12 @@ import (
 	"github.com/cirruslabs/cirrus-ci-agent/api"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/build/commandstatus"
 	"sync"
+	"time"
 )
 
 type Command struct {
-	status commandstatus.Status
+	status                commandstatus.Status
+	startTime, finishTime time.Time
 
 	// Original Protocol Buffers structure for reference
 	ProtoCommand *api.Command
@@ -28,4 +30,65 @@ func (command *Command) SetStatus(status commandstatus.Status) {
 	defer command.Mutex.Unlock()
 
 	command.status = status
+
+	if status != commandstatus.Undefined {
+		command.finishTime = time.Now()
+	}
+}
+
+// Reset returns the command to its initial state.
+func (command *Command) Reset() {
+	command.Mutex.Lock()
+	defer command.Mutex.Unlock()
+
+	command.status = commandstatus.Undefined
+	command.startTime = time.Time{}
+	command.finishTime = time.Time{}
+}
+
+// MarkStarted records the time the command has started at, only the first call has an effect.
+func (command *Command) MarkStarted() {
+	command.Mutex.Lock()
+	defer command.Mutex.Unlock()
+
+	if command.startTime.IsZero() {
+		command.startTime = time.Now()
+	}
+}
+
+// Duration returns how long the command took to run or zero if it's unknown.
+func (command *Command) Duration() time.Duration {
+	command.Mutex.RLock()
+	defer command.Mutex.RUnlock()
+
+	if command.startTime.IsZero() || command.finishTime.IsZero() {
+		return 0
+	}
+
+	return command.finishTime.Sub(command.startTime)
+}
+
+// Timing returns the time the command has started and finished at, each of which is zero if it's unknown.
+func (command *Command) Timing() (time.Time, time.Time) {
+	command.Mutex.RLock()
+	defer command.Mutex.RUnlock()
+
+	return command.startTime, command.finishTime
+}
+
+// Kind returns a human-readable kind of the command's instruction (e.g. "script")
+// or an empty string if the instruction is not known.
+func (command *Command) Kind() string {
+	switch command.ProtoCommand.Instruction.(type) {
+	case *api.Command_ScriptInstruction:
+		return "script"
+	case *api.Command_BackgroundScriptInstruction:
+		return "background script"
+	case *api.Command_CacheInstruction:
+		return "cache"
+	case *api.Command_ArtifactsInstruction:
+		return "artifacts"
+	default:
+		return ""
+	}
 }
@@ -0,0 +1,12 @@
+package build
+
+import "github.com/cirruslabs/cirrus-cli/internal/executor/cache"
+
+type Option func(*Build)
+
+// WithCache makes the build use the specified cache instead of the project's local cache.
+func WithCache(c cache.Cache) Option {
+	return func(b *Build) {
+		b.Cache = c
+	}
+}
@@ -0,0 +1,20 @@
+package build
+
+import (
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// AdditionalTaskProperties returns the CLI-specific task properties
+// to be passed to parser.WithAdditionalTaskProperties().
+func AdditionalTaskProperties() []*descriptor.FieldDescriptorProto {
+	autoRetryName := AutoRetryProperty
+	autoRetryType := descriptorpb.FieldDescriptorProto_TYPE_STRING
+
+	return []*descriptor.FieldDescriptorProto{
+		{
+			Name: &autoRetryName,
+			Type: &autoRetryType,
+		},
+	}
+}
@@ -20,18 +20,26 @@ import (
 
 const defaultTaskTimeout = 60 * time.Minute
 
+// AutoRetryProperty is a CLI-specific task property that specifies how many times
+// the task should be retried after an infrastructure failure.
+const AutoRetryProperty = "auto_retry"
+
 var ErrFailedToCreateTask = errors.New("failed to create task")
 
 type Task struct {
-	ID          int64
-	RequiredIDs []int64
-	Name        string
-	Labels      []string
-	status      taskstatus.Status
-	Instance    abstract.Instance
-	Timeout     time.Duration
-	Environment map[string]string
-	Commands    []*Command
+	ID            int64
+	RequiredIDs   []int64
+	Name          string
+	Labels        []string
+	status        taskstatus.Status
+	Instance      abstract.Instance
+	Timeout       time.Duration
+	AllowFailures bool
+	AutoRetries   int
+	Environment   map[string]string
+	Commands      []*Command
+
+	startTime, finishTime time.Time
 
 	// A mutex to guarantee safe accesses from both the main loop and gRPC server handlers
 	Mutex sync.RWMutex
@@ -79,19 +87,45 @@ func NewFromProto(protoTask *api.Task, logger logger.Lightweight) (*Task, error)
 		}
 	}
 
+	var allowFailures bool
+	if protoTask.Metadata != nil {
+		metadataAllowFailures, found := protoTask.Metadata.Properties["allow_failures"]
+		if found {
+			allowFailures, err = strconv.ParseBool(metadataAllowFailures)
+			if err != nil {
+				return nil, fmt.Errorf("%w: task %q: invalid allow_failures value %q: %v",
+					ErrFailedToCreateTask, protoTask.Name, metadataAllowFailures, err)
+			}
+		}
+	}
+
+	var autoRetries int
+	if protoTask.Metadata != nil {
+		metadataAutoRetries, found := protoTask.Metadata.Properties[AutoRetryProperty]
+		if found {
+			autoRetries, err = strconv.Atoi(metadataAutoRetries)
+			if err != nil || autoRetries < 0 {
+				return nil, fmt.Errorf("%w %q: %s should be a non-negative number of retries, got %q",
+					ErrFailedToCreateTask, protoTask.Name, AutoRetryProperty, metadataAutoRetries)
+			}
+		}
+	}
+
 	var uniqueLabels []string
 	if protoTask.Metadata != nil {
 		uniqueLabels = protoTask.Metadata.UniqueLabels
 	}
 	return &Task{
-		ID:          protoTask.LocalGroupId,
-		RequiredIDs: protoTask.RequiredGroups,
-		Name:        protoTask.Name,
-		Labels:      uniqueLabels,
-		Instance:    inst,
-		Timeout:     timeout,
-		Environment: protoTask.Environment,
-		Commands:    wrappedCommands,
+		ID:            protoTask.LocalGroupId,
+		RequiredIDs:   protoTask.RequiredGroups,
+		Name:          protoTask.Name,
+		Labels:        uniqueLabels,
+		Instance:      inst,
+		Timeout:       timeout,
+		AllowFailures: allowFailures,
+		AutoRetries:   autoRetries,
+		Environment:   protoTask.Environment,
+		Commands:      wrappedCommands,
 	}, nil
 }
 
@@ -118,6 +152,16 @@ func (task *Task) UniqueDescription() string {
 	return fmt.Sprintf("'%s' %s (%s)", name, taskMessagePart, strings.Join(task.Labels, " "))
 }
 
+// UniqueName returns the task's name extended with its labels (if any), which, unlike the name
+// alone, distinguishes the task from the other tasks generated by the same matrix.
+func (task *Task) UniqueName() string {
+	if len(task.Labels) == 0 {
+		return task.Name
+	}
+
+	return fmt.Sprintf("%s (%s)", task.Name, strings.Join(task.Labels, " "))
+}
+
 func (task *Task) FailedAtLeastOnce() bool {
 	for _, command := range task.Commands {
 		if command.Status() == commandstatus.Failure {
@@ -164,6 +208,42 @@ func (task *Task) SetStatus(status taskstatus.Status) {
 	task.status = status
 }
 
+// MarkStarted records the time the task has started at.
+func (task *Task) MarkStarted() {
+	task.Mutex.Lock()
+	defer task.Mutex.Unlock()
+
+	task.startTime = time.Now()
+	task.finishTime = time.Time{}
+}
+
+// MarkFinished records the time the task has finished at.
+func (task *Task) MarkFinished() {
+	task.Mutex.Lock()
+	defer task.Mutex.Unlock()
+
+	task.finishTime = time.Now()
+}
+
+// Duration returns how long the task took to run or zero if it wasn't run.
+func (task *Task) Duration() time.Duration {
+	task.Mutex.RLock()
+	defer task.Mutex.RUnlock()
+
+	if task.startTime.IsZero() || task.finishTime.IsZero() {
+		return 0
+	}
+
+	return task.finishTime.Sub(task.startTime)
+}
+
+// ResetCommands forgets the command statuses to be able to run the task again.
+func (task *Task) ResetCommands() {
+	for _, command := range task.Commands {
+		command.Reset()
+	}
+}
+
 func (task *Task) GetCommand(name string) *Command {
 	for _, command := range task.Commands {
 		if command.ProtoCommand.Name == name {
@@ -1,8 +1,10 @@
 package build_test
 
 import (
+	"errors"
 	"github.com/cirruslabs/cirrus-ci-agent/api"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/commandstatus"
 	"github.com/cirruslabs/cirrus-cli/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -51,3 +53,81 @@ func TestCloneInterception(t *testing.T) {
 		})
 	}
 }
+
+// TestAllowFailures ensures that the allow_failures property is picked up from the task's metadata.
+func TestAllowFailures(t *testing.T) {
+	task, err := build.NewFromProto(&api.Task{
+		Metadata: &api.Task_Metadata{
+			Properties: map[string]string{
+				"allow_failures": "true",
+			},
+		},
+		Instance: testutil.GetBasicContainerInstance(t, "debian:latest"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, task.AllowFailures)
+
+	_, err = build.NewFromProto(&api.Task{
+		Name: "main",
+		Metadata: &api.Task_Metadata{
+			Properties: map[string]string{
+				"allow_failures": "sometimes",
+			},
+		},
+		Instance: testutil.GetBasicContainerInstance(t, "debian:latest"),
+	}, nil)
+	assert.True(t, errors.Is(err, build.ErrFailedToCreateTask))
+}
+
+// TestAutoRetries ensures that the auto_retry property is picked up from the task's metadata.
+func TestAutoRetries(t *testing.T) {
+	newTask := func(autoRetry string) (*build.Task, error) {
+		return build.NewFromProto(&api.Task{
+			Metadata: &api.Task_Metadata{
+				Properties: map[string]string{
+					build.AutoRetryProperty: autoRetry,
+				},
+			},
+			Instance: testutil.GetBasicContainerInstance(t, "debian:latest"),
+		}, nil)
+	}
+
+	task, err := newTask("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, task.AutoRetries)
+
+	for _, invalid := range []string{"-1", "many"} {
+		_, err := newTask(invalid)
+		assert.True(t, errors.Is(err, build.ErrFailedToCreateTask))
+	}
+}
+
+// TestResetCommands ensures that the commands of a task that's going to be retried are run from scratch.
+func TestResetCommands(t *testing.T) {
+	task, err := build.NewFromProto(&api.Task{
+		Commands: []*api.Command{{Name: "first"}, {Name: "second"}},
+		Instance: testutil.GetBasicContainerInstance(t, "debian:latest"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task.GetCommand("first").MarkStarted()
+	task.GetCommand("first").SetStatus(commandstatus.Success)
+	task.GetCommand("second").MarkStarted()
+	task.GetCommand("second").SetStatus(commandstatus.Failure)
+	assert.True(t, task.FailedAtLeastOnce())
+
+	task.ResetCommands()
+
+	assert.False(t, task.FailedAtLeastOnce())
+	for _, command := range task.Commands {
+		assert.Equal(t, commandstatus.Undefined, command.Status())
+		assert.Zero(t, command.Duration())
+	}
+}
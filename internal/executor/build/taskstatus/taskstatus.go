@@ -9,6 +9,8 @@ const (
 	Succeeded
 	Failed
 	TimedOut
+	Skipped
+	Aborted
 )
 
 func (status Status) String() string {
@@ -21,6 +23,10 @@ func (status Status) String() string {
 		return "failed"
 	case TimedOut:
 		return "timed out"
+	case Skipped:
+		return "skipped"
+	case Aborted:
+		return "aborted"
 	default:
 		return fmt.Sprintf("entered unhandled status %d", int(status))
 	}
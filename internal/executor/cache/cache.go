@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -19,60 +20,114 @@ var (
 	ErrInternal           = errors.New("internal cache error")
 )
 
-type Cache struct {
+// Cache stores the blobs uploaded by the tasks, so that they can be retrieved by the subsequent runs.
+type Cache interface {
+	Get(key string) (io.ReadCloser, error)
+	Info(key string) (*Entry, error)
+	Put(key string) (PutOperation, error)
+}
+
+// PutOperation receives the blob's contents and makes it available once finalized.
+type PutOperation interface {
+	io.Writer
+	Finalize() error
+
+	// Abort releases the resources held by the operation that won't be finalized
+	// (or whose finalization has failed), it's a no-op after a successful Finalize().
+	Abort()
+}
+
+// Local is a Cache that stores the blobs in the local filesystem.
+type Local struct {
 	namespaceDir string
+	keysDir      string
+	maxSize      uint64
 }
 
-func New(dir string, namespace string) (*Cache, error) {
-	if dir == "" {
-		userCacheDir, err := os.UserCacheDir()
-		if err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrFailedToInitialize, err)
-		}
-		dir = userCacheDir
+func New(dir string, namespace string, opts ...Option) (*Local, error) {
+	c, err := Open(dir, namespace, opts...)
+	if err != nil {
+		return nil, err
 	}
 
-	namespaceDir := filepath.Join(dir, "cirrus", "projects", namespace)
-
-	// Create a base directory, ignoring ErrExist since it may already be created
+	// Create base directories, ignoring ErrExist since they may already be created
 	// by a previous or parallel invocation of the CLI
-	if err := os.MkdirAll(namespaceDir, 0700); err != nil {
-		if !os.IsExist(err) {
-			return nil, fmt.Errorf("%w: %v", ErrFailedToInitialize, err)
+	for _, baseDir := range []string{c.namespaceDir, c.keysDir} {
+		if err := os.MkdirAll(baseDir, 0700); err != nil {
+			if !os.IsExist(err) {
+				return nil, fmt.Errorf("%w: %v", ErrFailedToInitialize, err)
+			}
 		}
 	}
 
-	return &Cache{
-		namespaceDir: namespaceDir,
-	}, nil
+	return c, nil
 }
 
-func (c *Cache) Get(key string) (*os.File, error) {
+// Open is like New, but doesn't create the cache's directories, so that the cache
+// can be inspected and cleaned up without changing anything on the disk otherwise.
+func Open(dir string, namespace string, opts ...Option) (*Local, error) {
+	dir, err := resolveDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Local{
+		namespaceDir: filepath.Join(dir, "cirrus", "projects", namespace),
+		keysDir:      filepath.Join(dir, "cirrus", "keys", namespace),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// resolveDir returns the directory where the local cache is stored, which defaults to the user's cache directory.
+func resolveDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFailedToInitialize, err)
+	}
+
+	return userCacheDir, nil
+}
+
+func (c *Local) Get(key string) (io.ReadCloser, error) {
 	file, err := os.OpenFile(c.blobPath(key), os.O_RDONLY, 0)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, ErrBlobNotFound
 		}
-		return file, fmt.Errorf("%w: %v", ErrInternal, err)
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
 	}
 
+	c.touch(key)
+
 	return file, nil
 }
 
-func (c *Cache) Put(key string) (*PutOperation, error) {
-	tmpBlobFile, err := ioutil.TempFile(c.namespaceDir, ".temporary-blob-")
+func (c *Local) Put(key string) (PutOperation, error) {
+	tmpBlobFile, err := ioutil.TempFile(c.namespaceDir, temporaryBlobPrefix)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
 	}
 
-	return &PutOperation{
+	return &localPutOperation{
+		cache:         c,
+		key:           key,
 		tmpBlobFile:   tmpBlobFile,
 		tmpBlobWriter: bufio.NewWriterSize(tmpBlobFile, bufSize),
 		finalBlobPath: c.blobPath(key),
 	}, nil
 }
 
-func (c *Cache) blobPath(key string) string {
+func (c *Local) blobPath(key string) string {
 	if needsSanitization(key) {
 		keyHash := sha256.Sum256([]byte(key))
 		key = fmt.Sprintf("%x", keyHash)
@@ -3,13 +3,16 @@ package cache_test
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/cache"
 	"github.com/cirruslabs/cirrus-cli/internal/testutil"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestKeySanitization ensures that potentially problematic keys are sanitized.
@@ -91,7 +94,7 @@ func getRandomBlob(t *testing.T, size int) []byte {
 	return buf
 }
 
-func cacheWrite(t *testing.T, c *cache.Cache, key string, data []byte) {
+func cacheWrite(t *testing.T, c cache.Cache, key string, data []byte) {
 	putOp, err := c.Put(key)
 	if err != nil {
 		t.Fatal(err)
@@ -106,7 +109,7 @@ func cacheWrite(t *testing.T, c *cache.Cache, key string, data []byte) {
 	}
 }
 
-func cacheRead(t *testing.T, c *cache.Cache, key string) []byte {
+func cacheRead(t *testing.T, c cache.Cache, key string) []byte {
 	file, err := c.Get(key)
 	if err != nil {
 		t.Fatal(err)
@@ -123,3 +126,143 @@ func cacheRead(t *testing.T, c *cache.Cache, key string) []byte {
 
 	return data
 }
+
+// TestEntries ensures that the blobs can be listed with their original keys, inspected and removed.
+func TestEntries(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	c, err := cache.New(dir, "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheWrite(t, c, "1/2/3", []byte("hashed key"))
+	cacheWrite(t, c, "simple", []byte("simple key"))
+
+	entries, err := c.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "1/2/3", entries[0].Key)
+	require.EqualValues(t, len("hashed key"), entries[0].Size)
+	require.Equal(t, "simple", entries[1].Key)
+
+	entry, err := c.Info("1/2/3")
+	require.NoError(t, err)
+	require.Equal(t, "1/2/3", entry.Key)
+
+	require.NoError(t, c.Delete("simple"))
+	require.True(t, errors.Is(c.Delete("simple"), cache.ErrBlobNotFound))
+
+	_, err = c.Info("simple")
+	require.True(t, errors.Is(err, cache.ErrBlobNotFound))
+
+	require.NoError(t, c.Clear())
+
+	entries, err = c.Entries()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// TestPrune ensures that the stale and the least recently accessed blobs are pruned first.
+func TestPrune(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	c, err := cache.New(dir, "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	accessTimes := map[string]time.Time{
+		"old":    now.Add(-48 * time.Hour),
+		"recent": now.Add(-2 * time.Hour),
+		"newest": now,
+	}
+
+	for key, accessTime := range accessTimes {
+		cacheWrite(t, c, key, []byte("10 bytes!!"))
+		setAccessTime(t, dir, "project", key, accessTime)
+	}
+
+	pruned, err := c.Prune(24*time.Hour, 0)
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	require.Equal(t, "old", pruned[0].Key)
+
+	pruned, err = c.Prune(0, 10)
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	require.Equal(t, "recent", pruned[0].Key)
+
+	entries, err := c.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "newest", entries[0].Key)
+}
+
+func setAccessTime(t *testing.T, dir string, namespace string, key string, accessTime time.Time) {
+	for _, path := range []string{
+		filepath.Join(dir, "cirrus", "projects", namespace, key),
+		filepath.Join(dir, "cirrus", "keys", namespace, key),
+	} {
+		if err := os.Chtimes(path, accessTime, accessTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestEviction ensures that the least recently used blobs are evicted once the cache exceeds its size budget.
+func TestEviction(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	c, err := cache.New(dir, "project", cache.WithMaxSize(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	cacheWrite(t, c, "least-recent", []byte("10 bytes!!"))
+	setAccessTime(t, dir, "project", "least-recent", now.Add(-2*time.Hour))
+	cacheWrite(t, c, "most-recent", []byte("10 bytes!!"))
+	setAccessTime(t, dir, "project", "most-recent", now.Add(-time.Hour))
+
+	// Accessing the blob should prevent it from being evicted
+	cacheRead(t, c, "least-recent")
+
+	cacheWrite(t, c, "new", []byte("10 bytes!!"))
+	require.Equal(t, []string{"least-recent", "new"}, cacheKeys(t, c))
+
+	// The blob that was just written is never evicted, even if it exceeds the budget alone
+	cacheWrite(t, c, "huge", []byte("more than 20 bytes!!!"))
+	require.Equal(t, []string{"huge"}, cacheKeys(t, c))
+}
+
+// TestOpen ensures that opening a cache that doesn't exist yet doesn't create it.
+func TestOpen(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	c, err := cache.Open(dir, "project")
+	require.NoError(t, err)
+
+	require.Empty(t, cacheKeys(t, c))
+	_, err = c.Info("key")
+	require.True(t, errors.Is(err, cache.ErrBlobNotFound))
+	require.NoError(t, c.Clear())
+
+	require.NoDirExists(t, filepath.Join(dir, "cirrus"))
+}
+
+func cacheKeys(t *testing.T, c *cache.Local) []string {
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+
+	return keys
+}
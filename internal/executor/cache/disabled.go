@@ -0,0 +1,31 @@
+package cache
+
+import "io"
+
+// Disabled is a Cache that never has any blobs and discards the new ones, which is useful
+// when the build is only prepared for inspection and shouldn't touch the disk.
+type Disabled struct{}
+
+func (Disabled) Get(key string) (io.ReadCloser, error) {
+	return nil, ErrBlobNotFound
+}
+
+func (Disabled) Info(key string) (*Entry, error) {
+	return nil, ErrBlobNotFound
+}
+
+func (Disabled) Put(key string) (PutOperation, error) {
+	return disabledPutOperation{}, nil
+}
+
+type disabledPutOperation struct{}
+
+func (disabledPutOperation) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (disabledPutOperation) Finalize() error {
+	return nil
+}
+
+func (disabledPutOperation) Abort() {}
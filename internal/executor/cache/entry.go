@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// temporaryBlobPrefix is used for the blobs that are still being written.
+const temporaryBlobPrefix = ".temporary-blob-"
+
+type Entry struct {
+	Key        string
+	Path       string
+	Size       int64
+	CreatedAt  time.Time
+	AccessedAt time.Time
+}
+
+// Entries returns all of the blobs stored in the cache, sorted by their keys.
+func (c *Local) Entries() ([]*Entry, error) {
+	fileInfos, err := ioutil.ReadDir(c.namespaceDir)
+	if err != nil {
+		// The cache opened with Open() might've never been created
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	var entries []*Entry
+
+	for _, fileInfo := range fileInfos {
+		name := fileInfo.Name()
+
+		if fileInfo.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		entries = append(entries, c.entryFromFileInfo(fileInfo))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries, nil
+}
+
+// Info returns the blob stored under the specified key.
+func (c *Local) Info(key string) (*Entry, error) {
+	fileInfo, err := os.Stat(c.blobPath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return c.entryFromFileInfo(fileInfo), nil
+}
+
+// Delete removes the blob stored under the specified key.
+func (c *Local) Delete(key string) error {
+	if err := os.Remove(c.blobPath(key)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrBlobNotFound
+		}
+
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if err := os.Remove(c.keyPath(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// Prune removes the blobs that weren't accessed for longer than olderThan and then the least
+// recently accessed blobs until their total size fits into maxSize. Zero values disable the
+// respective criteria.
+func (c *Local) Prune(olderThan time.Duration, maxSize uint64) ([]*Entry, error) {
+	entries, totalSize, err := c.entriesByAccessTime()
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []*Entry
+
+	for _, entry := range entries {
+		isStale := olderThan != 0 && time.Since(entry.AccessedAt) > olderThan
+		isOverLimit := maxSize != 0 && totalSize > maxSize
+
+		if !isStale && !isOverLimit {
+			continue
+		}
+
+		// The blob might've been already removed by a concurrent invocation of the CLI
+		if err := c.Delete(entry.Key); err != nil && !errors.Is(err, ErrBlobNotFound) {
+			return pruned, err
+		}
+
+		totalSize -= uint64(entry.Size)
+		pruned = append(pruned, entry)
+	}
+
+	return pruned, nil
+}
+
+// evict removes the least recently used blobs (except for the one that was just
+// stored under the keep key) until their total size fits into the cache's budget.
+//
+// Eviction is best-effort: blobs that cannot be removed (e.g. because they're
+// still being read on Windows) are simply skipped and the concurrent evictions
+// by other CLI invocations are tolerated.
+func (c *Local) evict(keep string) {
+	if c.maxSize == 0 {
+		return
+	}
+
+	entries, totalSize, err := c.entriesByAccessTime()
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if totalSize <= c.maxSize {
+			break
+		}
+
+		if entry.Key == keep {
+			continue
+		}
+
+		if err := c.Delete(entry.Key); err != nil && !errors.Is(err, ErrBlobNotFound) {
+			continue
+		}
+
+		totalSize -= uint64(entry.Size)
+	}
+}
+
+// Clear removes all of the blobs stored in the cache.
+func (c *Local) Clear() error {
+	fileInfos, err := ioutil.ReadDir(c.namespaceDir)
+	if err != nil {
+		// The cache opened with Open() might've never been created
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	for _, fileInfo := range fileInfos {
+		// Don't interfere with the blobs that are still being written
+		if fileInfo.IsDir() || strings.HasPrefix(fileInfo.Name(), temporaryBlobPrefix) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(c.namespaceDir, fileInfo.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+
+		if err := os.Remove(filepath.Join(c.keysDir, fileInfo.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("%w: %v", ErrInternal, err)
+		}
+	}
+
+	return nil
+}
+
+// entriesByAccessTime returns the blobs sorted from the least to the most
+// recently accessed along with their total size.
+func (c *Local) entriesByAccessTime() ([]*Entry, uint64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].AccessedAt.Before(entries[j].AccessedAt)
+	})
+
+	var totalSize uint64
+	for _, entry := range entries {
+		totalSize += uint64(entry.Size)
+	}
+
+	return entries, totalSize, nil
+}
+
+func (c *Local) entryFromFileInfo(fileInfo os.FileInfo) *Entry {
+	blobPath := filepath.Join(c.namespaceDir, fileInfo.Name())
+
+	entry := &Entry{
+		Key:        fileInfo.Name(),
+		Path:       blobPath,
+		Size:       fileInfo.Size(),
+		CreatedAt:  fileInfo.ModTime(),
+		AccessedAt: fileInfo.ModTime(),
+	}
+
+	// Blobs created by the older CLI versions have no key file
+	keyPath := filepath.Join(c.keysDir, fileInfo.Name())
+
+	keyFileInfo, err := os.Stat(keyPath)
+	if err != nil {
+		return entry
+	}
+
+	if key, err := ioutil.ReadFile(keyPath); err == nil {
+		entry.Key = string(key)
+	}
+
+	if keyFileInfo.ModTime().After(entry.AccessedAt) {
+		entry.AccessedAt = keyFileInfo.ModTime()
+	}
+
+	return entry
+}
+
+func (c *Local) writeKeyFile(key string) error {
+	tmpKeyFile, err := ioutil.TempFile(c.keysDir, temporaryBlobPrefix)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	_, err = tmpKeyFile.WriteString(key)
+	if closeErr := tmpKeyFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpKeyFile.Name(), c.keyPath(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmpKeyFile.Name())
+
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// touch marks the blob as recently accessed.
+func (c *Local) touch(key string) {
+	now := time.Now()
+
+	if err := os.Chtimes(c.keyPath(key), now, now); err != nil && os.IsNotExist(err) {
+		_ = c.writeKeyFile(key)
+	}
+}
+
+// keyPath returns the path of a sidecar file that holds the blob's original key, which
+// might be hashed in the blob's name. Modification time of this file is updated on each
+// Get() and serves as the blob's last access time.
+func (c *Local) keyPath(key string) string {
+	return filepath.Join(c.keysDir, filepath.Base(c.blobPath(key)))
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// httpResponseHeaderTimeout limits the time the server is given to start responding.
+	httpResponseHeaderTimeout = time.Minute
+
+	// httpTimeout limits the total time of each request, including the transfer of the blob,
+	// so that an unresponsive server doesn't block the tasks forever.
+	httpTimeout = 30 * time.Minute
+)
+
+// HTTP is a Cache that stores the blobs on a remote server using a simple protocol
+// where each blob is available at <base URL>/<key> and is retrieved with GET,
+// inspected with HEAD and stored with PUT, which is compatible with the bazel-remote
+// and most of the S3-compatible object storages.
+type HTTP struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTP(baseURL string) (*HTTP, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedToInitialize, err)
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("%w: unsupported remote cache URL scheme %q", ErrFailedToInitialize, parsedURL.Scheme)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = httpResponseHeaderTimeout
+
+	return &HTTP{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   httpTimeout,
+		},
+	}, nil
+}
+
+func (c *HTTP) Get(key string) (io.ReadCloser, error) {
+	resp, err := c.do(http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (c *HTTP) Info(key string) (*Entry, error) {
+	resp, err := c.do(http.MethodHead, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	// Don't expose the credentials
+	blobURL := *resp.Request.URL
+	blobURL.User = nil
+
+	entry := &Entry{
+		Key:  key,
+		Path: blobURL.String(),
+		Size: resp.ContentLength,
+	}
+
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		entry.CreatedAt = lastModified
+		entry.AccessedAt = lastModified
+	}
+
+	return entry, nil
+}
+
+func (c *HTTP) Put(key string) (PutOperation, error) {
+	// Some of the servers (e.g. S3) require the Content-Length to be known
+	// in advance, so the blob is buffered in a temporary file first
+	tmpBlobFile, err := ioutil.TempFile("", "cirrus-remote-cache-")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return &httpPutOperation{
+		cache:       c,
+		key:         key,
+		tmpBlobFile: tmpBlobFile,
+	}, nil
+}
+
+func (c *HTTP) do(method string, key string, body io.Reader, contentLength int64) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.baseURL+"/"+url.PathEscape(key), body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	req.ContentLength = contentLength
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrBlobNotFound
+	}
+
+	return nil, fmt.Errorf("%w: remote cache responded with %s to %s request", ErrInternal, resp.Status, method)
+}
+
+type httpPutOperation struct {
+	cache       *HTTP
+	key         string
+	tmpBlobFile *os.File
+}
+
+func (putOp *httpPutOperation) Write(b []byte) (int, error) {
+	n, err := putOp.tmpBlobFile.Write(b)
+	if err != nil {
+		return n, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return n, nil
+}
+
+func (putOp *httpPutOperation) Abort() {
+	_ = putOp.tmpBlobFile.Close()
+	_ = os.Remove(putOp.tmpBlobFile.Name())
+}
+
+func (putOp *httpPutOperation) Finalize() error {
+	defer os.Remove(putOp.tmpBlobFile.Name())
+	defer putOp.tmpBlobFile.Close()
+
+	size, err := putOp.tmpBlobFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if _, err := putOp.tmpBlobFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	// Don't let the http.Client close the file prematurely and make sure
+	// that the empty blobs are not sent using the chunked encoding
+	var body io.Reader = ioutil.NopCloser(putOp.tmpBlobFile)
+	if size == 0 {
+		body = http.NoBody
+	}
+
+	resp, err := putOp.cache.do(http.MethodPut, putOp.key, body, size)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,180 @@
+package cache_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/cache"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// blobServer is an in-memory server that implements the remote cache protocol.
+type blobServer struct {
+	blobs map[string][]byte
+	lock  sync.Mutex
+}
+
+func newBlobServer(t *testing.T) (*blobServer, *httptest.Server) {
+	server := &blobServer{blobs: make(map[string][]byte)}
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	return server, httpServer
+}
+
+func (server *blobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		blob, ok := server.blobs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+		_, _ = w.Write(blob)
+	case http.MethodPut:
+		blob, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		server.blobs[r.URL.Path] = blob
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// TestHTTP ensures that the blobs can be stored, inspected and retrieved from the remote cache.
+func TestHTTP(t *testing.T) {
+	server, httpServer := newBlobServer(t)
+
+	c, err := cache.NewHTTP(httpServer.URL + "/cas/")
+	require.NoError(t, err)
+
+	_, err = c.Get("some/key")
+	assert.True(t, errors.Is(err, cache.ErrBlobNotFound))
+
+	cacheWrite(t, c, "some/key", []byte("contents"))
+	assert.Contains(t, server.blobs, "/cas/some/key")
+	assert.Equal(t, []byte("contents"), cacheRead(t, c, "some/key"))
+
+	entry, err := c.Info("some/key")
+	require.NoError(t, err)
+	assert.EqualValues(t, len("contents"), entry.Size)
+
+	// Empty blobs are valid too
+	cacheWrite(t, c, "empty", []byte{})
+	assert.Equal(t, []byte{}, cacheRead(t, c, "empty"))
+}
+
+// TestReadThrough ensures that the remote blobs are stored in the local cache
+// once retrieved and the new blobs end up in both caches.
+func TestReadThrough(t *testing.T) {
+	_, httpServer := newBlobServer(t)
+
+	local, err := cache.New(testutil.TempDir(t), "project")
+	require.NoError(t, err)
+
+	remote, err := cache.NewHTTP(httpServer.URL)
+	require.NoError(t, err)
+
+	c := cache.NewReadThrough(local, remote, nil)
+
+	// Blob stored by another machine
+	cacheWrite(t, remote, "remote", []byte("from remote"))
+	require.Empty(t, cacheKeys(t, local))
+
+	entry, err := c.Info("remote")
+	require.NoError(t, err)
+	assert.EqualValues(t, len("from remote"), entry.Size)
+
+	assert.Equal(t, []byte("from remote"), cacheRead(t, c, "remote"))
+	assert.Equal(t, []string{"remote"}, cacheKeys(t, local))
+
+	// Blob stored by this machine
+	cacheWrite(t, c, "local", []byte("from local"))
+	assert.Equal(t, []byte("from local"), cacheRead(t, local, "local"))
+	assert.Equal(t, []byte("from local"), cacheRead(t, remote, "local"))
+}
+
+var errReadOnly = errors.New("read-only cache")
+
+// unwritableCache is a Cache that fails to store any blobs.
+type unwritableCache struct {
+	cache.Cache
+}
+
+func (unwritableCache) Put(key string) (cache.PutOperation, error) {
+	return nil, errReadOnly
+}
+
+// TestReadThroughLocalFailure ensures that the remote blobs are still served
+// when they cannot be stored in the local cache.
+func TestReadThroughLocalFailure(t *testing.T) {
+	_, httpServer := newBlobServer(t)
+
+	local, err := cache.New(testutil.TempDir(t), "project")
+	require.NoError(t, err)
+
+	remote, err := cache.NewHTTP(httpServer.URL)
+	require.NoError(t, err)
+
+	c := cache.NewReadThrough(unwritableCache{local}, remote, nil)
+
+	cacheWrite(t, remote, "remote", []byte("from remote"))
+	assert.Equal(t, []byte("from remote"), cacheRead(t, c, "remote"))
+	assert.Empty(t, cacheKeys(t, local))
+}
+
+// TestReadThroughRemoteFailure ensures that the remote cache failures don't affect the local cache.
+func TestReadThroughRemoteFailure(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(httpServer.Close)
+
+	dir := testutil.TempDir(t)
+
+	local, err := cache.New(dir, "project")
+	require.NoError(t, err)
+
+	remote, err := cache.NewHTTP(httpServer.URL)
+	require.NoError(t, err)
+
+	c := cache.NewReadThrough(local, remote, nil)
+
+	// Remote cache failures are treated as misses
+	_, err = c.Get("missing")
+	assert.True(t, errors.Is(err, cache.ErrBlobNotFound))
+
+	_, err = c.Info("missing")
+	assert.True(t, errors.Is(err, cache.ErrBlobNotFound))
+
+	// Blobs are still stored locally
+	cacheWrite(t, c, "key", []byte("contents"))
+	assert.Equal(t, []byte("contents"), cacheRead(t, local, "key"))
+
+	// Aborted operations leave nothing behind
+	putOp, err := c.Put("aborted")
+	require.NoError(t, err)
+	_, err = putOp.Write([]byte("partial"))
+	require.NoError(t, err)
+	putOp.Abort()
+
+	blobFiles, err := ioutil.ReadDir(filepath.Join(dir, "cirrus", "projects", "project"))
+	require.NoError(t, err)
+	require.Len(t, blobFiles, 1)
+	assert.Equal(t, "key", blobFiles[0].Name())
+}
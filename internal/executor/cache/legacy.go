@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LegacyNamespace returns the name of the namespace that was used for the project's cache
+// before the namespaces were derived from the project's identity (see Namespace()).
+func LegacyNamespace(projectDir string) string {
+	if absoluteProjectDir, err := filepath.Abs(projectDir); err == nil {
+		projectDir = absoluteProjectDir
+	}
+
+	return filepath.Base(projectDir)
+}
+
+// RemoveLegacyNamespace removes the cache stored by the older versions of the CLI in the project's
+// legacy namespace.
+//
+// The legacy namespace is shared by all of the projects with the same directory name, so there's
+// no way to tell which project the cache belongs to and it's only removed at the user's request.
+func RemoveLegacyNamespace(dir string, projectDir string) error {
+	dir, err := resolveDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range []string{"projects", "keys"} {
+		legacyDir := filepath.Join(dir, "cirrus", kind, LegacyNamespace(projectDir))
+
+		if err := os.RemoveAll(legacyDir); err != nil {
+			return fmt.Errorf("%w: failed to remove the legacy cache: %v", ErrInternal, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"github.com/go-git/go-git/v5/config"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// namespaceHashBytes is the number of the project identity hash bytes used in a namespace name.
+const namespaceHashBytes = 8
+
+// defaultNamespaceRemote is the Git remote that identifies the project unless specified otherwise.
+const defaultNamespaceRemote = "origin"
+
+type NamespaceOptions struct {
+	// ShareWorktrees makes the linked worktrees of the repository use the same namespace
+	// as the main worktree, instead of each having its own.
+	ShareWorktrees bool
+
+	// PerBranch gives each branch of the repository its own namespace, similarly to
+	// how the cache is scoped for the pull requests in Cirrus CI.
+	PerBranch bool
+
+	// Remote is the name of the Git remote whose URL identifies the project,
+	// defaults to "origin".
+	Remote string
+}
+
+// Namespace returns the name of the cache namespace for the project in the specified directory.
+//
+// Projects are identified by their Git repository's remote URL, falling back to the repository
+// location or the project directory path when there's no remote or no repository at all, so that
+// the projects with the same directory name never share their caches.
+func Namespace(projectDir string, opts NamespaceOptions) string {
+	if absoluteProjectDir, err := filepath.Abs(projectDir); err == nil {
+		projectDir = absoluteProjectDir
+	}
+
+	name := filepath.Base(projectDir)
+	identity := []string{projectDir}
+
+	if repo := findRepository(projectDir); repo != nil {
+		name = filepath.Base(repo.worktreeDir)
+		identity = []string{repo.commonDir}
+
+		remoteName := opts.Remote
+		if remoteName == "" {
+			remoteName = defaultNamespaceRemote
+		}
+
+		if remoteURL := repo.remoteURL(remoteName); remoteURL != "" {
+			name = path.Base(remoteURL)
+			identity = []string{remoteURL}
+		}
+
+		if !opts.ShareWorktrees && repo.gitDir != repo.commonDir {
+			identity = append(identity, "worktree:"+repo.gitDir)
+		}
+
+		if branch := repo.branch(); opts.PerBranch && branch != "" {
+			identity = append(identity, "branch:"+branch)
+		}
+	}
+
+	identityHash := sha256.Sum256([]byte(strings.Join(identity, "\n")))
+
+	return fmt.Sprintf("%s-%x", sanitizeNamespaceName(name), identityHash[:namespaceHashBytes])
+}
+
+type repository struct {
+	// Directory with the checked out files
+	worktreeDir string
+
+	// Worktree-specific Git directory (e.g. HEAD is stored here)
+	gitDir string
+
+	// Git directory shared by all of the repository's worktrees (e.g. config is stored here)
+	commonDir string
+}
+
+// findRepository locates the Git repository that contains the specified directory.
+func findRepository(dir string) *repository {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+
+		if fileInfo, err := os.Stat(dotGit); err == nil {
+			if fileInfo.IsDir() {
+				return &repository{worktreeDir: dir, gitDir: dotGit, commonDir: dotGit}
+			}
+
+			return linkedWorktree(dir, dotGit)
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return nil
+		}
+		dir = parentDir
+	}
+}
+
+// linkedWorktree resolves the Git directories of a worktree created with "git worktree add",
+// which has a .git file containing the path to the worktree-specific Git directory.
+func linkedWorktree(worktreeDir string, dotGitFile string) *repository {
+	dotGitContents, err := ioutil.ReadFile(dotGitFile)
+	if err != nil {
+		return nil
+	}
+
+	const gitDirPrefix = "gitdir:"
+
+	line := strings.TrimSpace(string(dotGitContents))
+	if !strings.HasPrefix(line, gitDirPrefix) {
+		return nil
+	}
+
+	gitDir := resolvePath(worktreeDir, strings.TrimSpace(strings.TrimPrefix(line, gitDirPrefix)))
+
+	// Submodules have no "commondir" file and use their Git directory exclusively
+	commonDir := gitDir
+	if commonDirContents, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = resolvePath(gitDir, strings.TrimSpace(string(commonDirContents)))
+	}
+
+	return &repository{worktreeDir: worktreeDir, gitDir: gitDir, commonDir: commonDir}
+}
+
+// remoteURL returns the normalized URL of the specified remote, so that the HTTPS
+// and SSH URLs of the same repository result in the same identity.
+func (repo *repository) remoteURL(remoteName string) string {
+	configBytes, err := ioutil.ReadFile(filepath.Join(repo.commonDir, "config"))
+	if err != nil {
+		return ""
+	}
+
+	repoConfig, err := config.ReadConfig(bytes.NewReader(configBytes))
+	if err != nil {
+		return ""
+	}
+
+	remote, ok := repoConfig.Remotes[remoteName]
+	if !ok || len(remote.URLs) < 1 {
+		return ""
+	}
+
+	return normalizeRemoteURL(remote.URLs[0])
+}
+
+// branch returns the name of the currently checked out branch or an empty string if the HEAD is detached.
+func (repo *repository) branch() string {
+	headBytes, err := ioutil.ReadFile(filepath.Join(repo.gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	const branchRefPrefix = "ref: refs/heads/"
+
+	head := strings.TrimSpace(string(headBytes))
+	if !strings.HasPrefix(head, branchRefPrefix) {
+		return ""
+	}
+
+	return strings.TrimPrefix(head, branchRefPrefix)
+}
+
+// normalizeRemoteURL converts URLs like "https://user@github.com/org/repo.git"
+// and "git@github.com:org/repo" to "github.com/org/repo".
+func normalizeRemoteURL(remoteURL string) string {
+	normalized := remoteURL
+
+	if schemeEnd := strings.Index(normalized, "://"); schemeEnd != -1 {
+		normalized = normalized[schemeEnd+len("://"):]
+	} else if colon := strings.Index(normalized, ":"); colon != -1 && !strings.Contains(normalized[:colon], "/") {
+		// SCP-like syntax
+		normalized = normalized[:colon] + "/" + normalized[colon+1:]
+	}
+
+	if at := strings.Index(normalized, "@"); at != -1 && !strings.Contains(normalized[:at], "/") {
+		normalized = normalized[at+1:]
+	}
+
+	normalized = strings.TrimSuffix(strings.TrimSuffix(normalized, "/"), ".git")
+
+	return strings.ToLower(normalized)
+}
+
+func resolvePath(baseDir string, target string) string {
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(baseDir, target)
+	}
+
+	return filepath.Clean(target)
+}
+
+// sanitizeNamespaceName makes the human-readable part of the namespace safe to be used as a directory name.
+func sanitizeNamespaceName(name string) string {
+	sanitized := strings.Map(func(c rune) rune {
+		if needsSanitization(string(c)) && c != '.' {
+			return '_'
+		}
+
+		return c
+	}, name)
+
+	if strings.Trim(sanitized, ".") == "" {
+		return "project"
+	}
+
+	return sanitized
+}
@@ -0,0 +1,119 @@
+package cache_test
+
+import (
+	"github.com/cirruslabs/cirrus-cli/internal/executor/cache"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+}
+
+// fakeRepository creates a minimal Git repository with the specified "origin" remote URL.
+func fakeRepository(t *testing.T, dir string, remoteURL string) {
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, ".git", "config"), "[remote \"origin\"]\n\turl = "+remoteURL+"\n")
+}
+
+// fakeWorktree creates a linked worktree of the repository checked out at the specified branch.
+func fakeWorktree(t *testing.T, repoDir string, dir string, branch string) {
+	gitDir := filepath.Join(repoDir, ".git", "worktrees", filepath.Base(dir))
+
+	writeFile(t, filepath.Join(dir, ".git"), "gitdir: "+gitDir+"\n")
+	writeFile(t, filepath.Join(gitDir, "commondir"), "../..\n")
+	writeFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/"+branch+"\n")
+}
+
+// TestNamespaceCollisions ensures that the projects with the same directory name
+// don't share the cache unless they're clones of the same repository.
+func TestNamespaceCollisions(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	first := filepath.Join(dir, "first", "app")
+	second := filepath.Join(dir, "second", "app")
+	require.NoError(t, os.MkdirAll(first, 0700))
+	require.NoError(t, os.MkdirAll(second, 0700))
+
+	firstNamespace := cache.Namespace(first, cache.NamespaceOptions{})
+	secondNamespace := cache.Namespace(second, cache.NamespaceOptions{})
+	assert.True(t, strings.HasPrefix(firstNamespace, "app-"))
+	assert.NotEqual(t, firstNamespace, secondNamespace)
+
+	// Different repositories
+	fakeRepository(t, first, "https://github.com/first/app.git")
+	fakeRepository(t, second, "https://github.com/second/app.git")
+	assert.NotEqual(t, cache.Namespace(first, cache.NamespaceOptions{}),
+		cache.Namespace(second, cache.NamespaceOptions{}))
+
+	// Clones of the same repository
+	fakeRepository(t, second, "git@github.com:first/app")
+	assert.Equal(t, cache.Namespace(first, cache.NamespaceOptions{}),
+		cache.Namespace(filepath.Join(second, "subdir"), cache.NamespaceOptions{}))
+}
+
+// TestNamespaceRemote ensures that the project can be identified by a remote other than "origin".
+func TestNamespaceRemote(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	fakeRepository(t, first, "https://github.com/org/app")
+	writeFile(t, filepath.Join(second, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(second, ".git", "config"), "[remote \"upstream\"]\n\turl = git@github.com:org/app\n")
+
+	assert.NotEqual(t, cache.Namespace(first, cache.NamespaceOptions{}),
+		cache.Namespace(second, cache.NamespaceOptions{}))
+	assert.Equal(t, cache.Namespace(first, cache.NamespaceOptions{}),
+		cache.Namespace(second, cache.NamespaceOptions{Remote: "upstream"}))
+}
+
+// TestNamespaceWorktrees ensures that the worktrees and branches can be given their own namespaces.
+func TestNamespaceWorktrees(t *testing.T) {
+	dir := testutil.TempDir(t)
+
+	main := filepath.Join(dir, "app")
+	worktree := filepath.Join(dir, "app-feature")
+	fakeRepository(t, main, "https://github.com/org/app")
+	fakeWorktree(t, main, worktree, "feature")
+
+	isolated := cache.NamespaceOptions{}
+	assert.NotEqual(t, cache.Namespace(main, isolated), cache.Namespace(worktree, isolated))
+
+	shared := cache.NamespaceOptions{ShareWorktrees: true}
+	assert.Equal(t, cache.Namespace(main, shared), cache.Namespace(worktree, shared))
+
+	sharedPerBranch := cache.NamespaceOptions{ShareWorktrees: true, PerBranch: true}
+	assert.NotEqual(t, cache.Namespace(main, sharedPerBranch), cache.Namespace(worktree, sharedPerBranch))
+
+	fakeWorktree(t, main, worktree, "main")
+	assert.Equal(t, cache.Namespace(main, sharedPerBranch), cache.Namespace(worktree, sharedPerBranch))
+}
+
+// TestRemoveLegacyNamespace ensures that the cache stored by the older versions of the CLI
+// is left intact unless explicitly removed.
+func TestRemoveLegacyNamespace(t *testing.T) {
+	cacheDir := testutil.TempDir(t)
+	projectDir := filepath.Join(testutil.TempDir(t), "app")
+	require.NoError(t, os.MkdirAll(projectDir, 0700))
+
+	legacy, err := cache.New(cacheDir, cache.LegacyNamespace(projectDir))
+	require.NoError(t, err)
+	cacheWrite(t, legacy, "key", []byte("contents"))
+
+	current, err := cache.New(cacheDir, cache.Namespace(projectDir, cache.NamespaceOptions{}))
+	require.NoError(t, err)
+	assert.Empty(t, cacheKeys(t, current))
+	assert.Equal(t, []string{"key"}, cacheKeys(t, legacy))
+
+	require.NoError(t, cache.RemoveLegacyNamespace(cacheDir, projectDir))
+	assert.NoDirExists(t, filepath.Join(cacheDir, "cirrus", "projects", "app"))
+	assert.NoDirExists(t, filepath.Join(cacheDir, "cirrus", "keys", "app"))
+}
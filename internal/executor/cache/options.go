@@ -0,0 +1,11 @@
+package cache
+
+type Option func(*Local)
+
+// WithMaxSize enables the eviction of the least recently used blobs
+// once the total size of the blobs exceeds the specified amount of bytes.
+func WithMaxSize(maxSize uint64) Option {
+	return func(c *Local) {
+		c.maxSize = maxSize
+	}
+}
@@ -6,13 +6,15 @@ import (
 	"os"
 )
 
-type PutOperation struct {
+type localPutOperation struct {
+	cache         *Local
+	key           string
 	tmpBlobFile   *os.File
 	tmpBlobWriter *bufio.Writer
 	finalBlobPath string
 }
 
-func (putOp *PutOperation) Write(b []byte) (int, error) {
+func (putOp *localPutOperation) Write(b []byte) (int, error) {
 	n, err := putOp.tmpBlobWriter.Write(b)
 	if err != nil {
 		return n, fmt.Errorf("%w: %v", ErrInternal, err)
@@ -21,7 +23,12 @@ func (putOp *PutOperation) Write(b []byte) (int, error) {
 	return n, nil
 }
 
-func (putOp *PutOperation) Finalize() error {
+func (putOp *localPutOperation) Abort() {
+	_ = putOp.tmpBlobFile.Close()
+	_ = os.Remove(putOp.tmpBlobFile.Name())
+}
+
+func (putOp *localPutOperation) Finalize() error {
 	// Close the wrapped buffered I/O writer and file, so that their respective internal buffers are flushed
 	if err := putOp.tmpBlobWriter.Flush(); err != nil {
 		return fmt.Errorf("%w: %v", ErrInternal, err)
@@ -30,10 +37,18 @@ func (putOp *PutOperation) Finalize() error {
 		return fmt.Errorf("%w: %v", ErrInternal, err)
 	}
 
+	// Remember the original key since it might be hashed in the blob's name
+	if err := putOp.cache.writeKeyFile(putOp.key); err != nil {
+		return err
+	}
+
 	// Atomically move the wrapped tmpBlobFile to it's final place
 	if err := os.Rename(putOp.tmpBlobFile.Name(), putOp.finalBlobPath); err != nil {
 		return fmt.Errorf("%w: %v", ErrInternal, err)
 	}
 
+	// Make room for the new blob if needed
+	putOp.cache.evict(putOp.key)
+
 	return nil
 }
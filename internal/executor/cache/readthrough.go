@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"errors"
+	"github.com/cirruslabs/echelon"
+	"github.com/cirruslabs/echelon/renderers"
+	"io"
+	"io/ioutil"
+)
+
+// ReadThrough is a Cache that serves the blobs from the local cache when possible,
+// falling back to the remote cache and populating the local cache on misses.
+// New blobs are stored in both caches.
+//
+// The local cache takes precedence, so the remote cache failures are only reported
+// as warnings and the remote cache is treated as if it simply had no blob.
+type ReadThrough struct {
+	local  Cache
+	remote Cache
+	logger *echelon.Logger
+}
+
+func NewReadThrough(local Cache, remote Cache, logger *echelon.Logger) *ReadThrough {
+	if logger == nil {
+		renderer := renderers.NewSimpleRenderer(ioutil.Discard, nil)
+		logger = echelon.NewLogger(echelon.InfoLevel, renderer)
+	}
+
+	return &ReadThrough{
+		local:  local,
+		remote: remote,
+		logger: logger,
+	}
+}
+
+func (c *ReadThrough) Get(key string) (io.ReadCloser, error) {
+	blob, err := c.local.Get(key)
+	if !errors.Is(err, ErrBlobNotFound) {
+		return blob, err
+	}
+
+	remoteBlob, err := c.remote.Get(key)
+	if err != nil {
+		return nil, c.remoteError(err)
+	}
+
+	// Serve the remote blob as is if it cannot be stored in the local cache
+	putOp, err := c.local.Put(key)
+	if err != nil {
+		c.logger.Warnf("failed to store the remote cache blob in the local cache: %v", err)
+
+		return remoteBlob, nil
+	}
+	defer remoteBlob.Close()
+
+	if _, err := io.Copy(putOp, remoteBlob); err != nil {
+		putOp.Abort()
+		return nil, c.remoteError(err)
+	}
+
+	if err := putOp.Finalize(); err != nil {
+		putOp.Abort()
+		return nil, err
+	}
+
+	return c.local.Get(key)
+}
+
+func (c *ReadThrough) Info(key string) (*Entry, error) {
+	entry, err := c.local.Info(key)
+	if !errors.Is(err, ErrBlobNotFound) {
+		return entry, err
+	}
+
+	entry, err = c.remote.Info(key)
+	if err != nil {
+		return nil, c.remoteError(err)
+	}
+
+	return entry, nil
+}
+
+func (c *ReadThrough) Put(key string) (PutOperation, error) {
+	localPutOp, err := c.local.Put(key)
+	if err != nil {
+		return nil, err
+	}
+
+	putOp := &readThroughPutOperation{
+		cache:      c,
+		localPutOp: localPutOp,
+	}
+
+	putOp.remotePutOp, err = c.remote.Put(key)
+	if err != nil {
+		c.remoteError(err)
+	}
+
+	return putOp, nil
+}
+
+// remoteError reports the remote cache failure (if it's not a simple cache miss)
+// and converts it to a cache miss.
+func (c *ReadThrough) remoteError(err error) error {
+	if !errors.Is(err, ErrBlobNotFound) {
+		c.logger.Warnf("remote cache failed, falling back to the local cache: %v", err)
+	}
+
+	return ErrBlobNotFound
+}
+
+type readThroughPutOperation struct {
+	cache       *ReadThrough
+	localPutOp  PutOperation
+	remotePutOp PutOperation
+}
+
+func (putOp *readThroughPutOperation) Write(b []byte) (int, error) {
+	n, err := putOp.localPutOp.Write(b)
+	if err != nil {
+		return n, err
+	}
+
+	if putOp.remotePutOp != nil {
+		if _, err := putOp.remotePutOp.Write(b); err != nil {
+			putOp.abortRemote(err)
+		}
+	}
+
+	return n, nil
+}
+
+func (putOp *readThroughPutOperation) Finalize() error {
+	if err := putOp.localPutOp.Finalize(); err != nil {
+		putOp.Abort()
+		return err
+	}
+
+	if putOp.remotePutOp != nil {
+		if err := putOp.remotePutOp.Finalize(); err != nil {
+			putOp.abortRemote(err)
+		}
+	}
+
+	return nil
+}
+
+func (putOp *readThroughPutOperation) Abort() {
+	putOp.localPutOp.Abort()
+
+	if putOp.remotePutOp != nil {
+		putOp.remotePutOp.Abort()
+	}
+}
+
+// abortRemote stops storing the blob in the remote cache, leaving only the local cache to store it.
+func (putOp *readThroughPutOperation) abortRemote(err error) {
+	putOp.cache.remoteError(err)
+	putOp.remotePutOp.Abort()
+	putOp.remotePutOp = nil
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/echelon"
+)
+
+const (
+	// RemoteModeReadThrough serves the blobs from the local cache when possible
+	// and uses the remote cache to share the blobs across the machines.
+	RemoteModeReadThrough = "read-through"
+
+	// RemoteModeRemoteOnly bypasses the local cache completely.
+	RemoteModeRemoteOnly = "remote-only"
+)
+
+var ErrUnsupportedRemoteMode = errors.New("unsupported remote cache mode")
+
+// RemoteModes returns the supported remote cache modes.
+func RemoteModes() []string {
+	return []string{RemoteModeReadThrough, RemoteModeRemoteOnly}
+}
+
+// WithRemote combines the local and remote caches according to the specified mode,
+// reporting the remote cache failures that are tolerated to the logger.
+func WithRemote(local Cache, remote Cache, mode string, logger *echelon.Logger) (Cache, error) {
+	switch mode {
+	case RemoteModeReadThrough:
+		return NewReadThrough(local, remote, logger), nil
+	case RemoteModeRemoteOnly:
+		return remote, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedRemoteMode, mode)
+	}
+}
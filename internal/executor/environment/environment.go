@@ -5,7 +5,6 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/google/uuid"
 	"strconv"
-	"strings"
 )
 
 func Merge(opts ...map[string]string) map[string]string {
@@ -65,7 +64,14 @@ func TaskInfo(taskName string, taskID int64) map[string]string {
 	}
 }
 
+// ProjectSpecific returns the variables describing the project's Git repository,
+// using the DefaultRemote to detect the repository variables.
 func ProjectSpecific(projectDir string) map[string]string {
+	return ProjectSpecificWithRemote(projectDir, DefaultRemote)
+}
+
+// ProjectSpecificWithRemote is like ProjectSpecific, but uses the specified Git remote.
+func ProjectSpecificWithRemote(projectDir string, remoteName string) map[string]string {
 	result := make(map[string]string)
 
 	repo, err := git.PlainOpen(projectDir)
@@ -73,7 +79,7 @@ func ProjectSpecific(projectDir string) map[string]string {
 		return result
 	}
 
-	addRemoteVariables(result, repo)
+	addRemoteVariables(result, repo, remoteName)
 	addBranchVariables(result, repo)
 	addCommitVariables(result, repo)
 	addTagVariables(result, repo)
@@ -81,8 +87,8 @@ func ProjectSpecific(projectDir string) map[string]string {
 	return result
 }
 
-func addRemoteVariables(envMap map[string]string, repo *git.Repository) {
-	remote, err := repo.Remote("origin")
+func addRemoteVariables(envMap map[string]string, repo *git.Repository, remoteName string) {
+	remote, err := repo.Remote(remoteName)
 	if err != nil {
 		return
 	}
@@ -91,39 +97,16 @@ func addRemoteVariables(envMap map[string]string, repo *git.Repository) {
 		return
 	}
 
-	url := remote.Config().URLs[0]
-
-	// Cut URL's prefix if it looks like a GitHub's one or bail out
-	cutURL := url
-	const httpsPrefix = "https://github.com/"
-	const sshPrefix = "git@github.com:"
-
-	switch {
-	case strings.HasPrefix(cutURL, httpsPrefix):
-		cutURL = strings.TrimPrefix(cutURL, httpsPrefix)
-	case strings.HasPrefix(cutURL, sshPrefix):
-		cutURL = strings.TrimPrefix(cutURL, sshPrefix)
-	default:
-		return
-	}
-
-	// Cut URL's suffix
-	const gitSuffix = ".git"
-	cutURL = strings.TrimSuffix(cutURL, gitSuffix)
-
-	// Extract the repository owner and name
-	const cleanURLParts = 2
-
-	parts := strings.Split(cutURL, "/")
-	if len(parts) != cleanURLParts {
+	remoteRepo, ok := parseRemoteURL(remote.Config().URLs[0])
+	if !ok {
 		return
 	}
 
-	envMap["CIRRUS_REPO_CLONE_URL"] = url
-	repoOwner, repoName := parts[0], parts[1]
-	envMap["CIRRUS_REPO_OWNER"] = repoOwner
-	envMap["CIRRUS_REPO_NAME"] = repoName
-	envMap["CIRRUS_REPO_FULL_NAME"] = fmt.Sprintf("%s/%s", repoOwner, repoName)
+	envMap["CIRRUS_REPO_CLONE_URL"] = remoteRepo.cloneURL
+	envMap["CIRRUS_REPO_CLONE_HOST"] = remoteRepo.host
+	envMap["CIRRUS_REPO_OWNER"] = remoteRepo.owner
+	envMap["CIRRUS_REPO_NAME"] = remoteRepo.name
+	envMap["CIRRUS_REPO_FULL_NAME"] = fmt.Sprintf("%s/%s", remoteRepo.owner, remoteRepo.name)
 }
 
 func addBranchVariables(envMap map[string]string, repo *git.Repository) {
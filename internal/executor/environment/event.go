@@ -0,0 +1,129 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event describes a CI event (branch push, tag push, pull request or cron build)
+// to emulate instead of the one derived from the project's Git repository.
+type Event struct {
+	Branch string
+	Tag    string
+	Cron   string
+
+	PR         int64
+	BaseBranch string
+	PRLabels   []string
+	PRDraft    bool
+}
+
+// eventVariables are the variables that depend on the kind of event that triggered the build.
+var eventVariables = []string{
+	"CIRRUS_BRANCH",
+	"CIRRUS_TAG",
+	"CIRRUS_CRON",
+	"CIRRUS_PR",
+	"CIRRUS_BASE_BRANCH",
+	"CIRRUS_HEAD_BRANCH",
+	"CIRRUS_PR_LABELS",
+	"CIRRUS_PR_DRAFT",
+}
+
+// IsEmpty returns true if no event emulation was requested.
+func (event *Event) IsEmpty() bool {
+	return event.Branch == "" && event.Tag == "" && event.Cron == "" && event.PR == 0 &&
+		event.BaseBranch == "" && len(event.PRLabels) == 0 && !event.PRDraft
+}
+
+// Validate ensures that the event is not self-contradictory.
+func (event *Event) Validate() error {
+	if event.PR < 0 {
+		return fmt.Errorf("%w: pull request number should be positive", ErrInvalidEvent)
+	}
+
+	var kinds []string
+	if event.Tag != "" {
+		kinds = append(kinds, "tag")
+	}
+	if event.Cron != "" {
+		kinds = append(kinds, "cron")
+	}
+	if event.PR != 0 {
+		kinds = append(kinds, "pull request")
+	}
+	if len(kinds) > 1 {
+		return fmt.Errorf("%w: can't emulate a %s build at the same time", ErrInvalidEvent,
+			strings.Join(kinds, " and "))
+	}
+
+	if event.PR == 0 && (event.BaseBranch != "" || len(event.PRLabels) != 0 || event.PRDraft) {
+		return fmt.Errorf("%w: base branch, labels and draft status only make sense for pull requests",
+			ErrInvalidEvent)
+	}
+
+	return nil
+}
+
+// Apply returns a copy of the project-specific environment with the event-related
+// variables replaced by the ones describing the emulated event.
+func (event *Event) Apply(projectEnv map[string]string) map[string]string {
+	result := Copy(projectEnv)
+
+	if event.IsEmpty() {
+		return result
+	}
+
+	for _, key := range eventVariables {
+		delete(result, key)
+	}
+
+	return Merge(result, event.Variables(projectEnv))
+}
+
+// Variables returns only the variables describing the emulated event. The branch
+// is taken from the project-specific environment unless specified explicitly.
+func (event *Event) Variables(projectEnv map[string]string) map[string]string {
+	result := make(map[string]string)
+
+	if event.IsEmpty() {
+		return result
+	}
+
+	branch := event.Branch
+	if branch == "" && event.Tag == "" {
+		branch = projectEnv["CIRRUS_BRANCH"]
+	}
+
+	switch {
+	case event.Tag != "":
+		result["CIRRUS_TAG"] = event.Tag
+	case event.Cron != "":
+		result["CIRRUS_CRON"] = event.Cron
+	case event.PR != 0:
+		pr := strconv.FormatInt(event.PR, 10)
+
+		result["CIRRUS_PR"] = pr
+		result["CIRRUS_PR_DRAFT"] = strconv.FormatBool(event.PRDraft)
+		result["CIRRUS_PR_LABELS"] = strings.Join(event.PRLabels, ",")
+		if event.BaseBranch != "" {
+			result["CIRRUS_BASE_BRANCH"] = event.BaseBranch
+		}
+		if branch != "" {
+			result["CIRRUS_HEAD_BRANCH"] = branch
+		}
+
+		// That's how Cirrus CI names the branches for pull requests
+		branch = "pull/" + pr
+	}
+
+	if branch != "" {
+		result["CIRRUS_BRANCH"] = branch
+	}
+
+	return result
+}
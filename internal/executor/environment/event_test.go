@@ -0,0 +1,83 @@
+package environment_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/environment"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var projectEnv = map[string]string{
+	"CIRRUS_BRANCH":         "feature",
+	"CIRRUS_TAG":            "v1.0.0",
+	"CIRRUS_CHANGE_IN_REPO": "abcdef",
+}
+
+// TestEventApply ensures that the variables derived from the Git repository
+// are replaced by the ones describing the emulated event.
+func TestEventApply(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Event    environment.Event
+		Expected map[string]string
+	}{
+		{"no event", environment.Event{}, projectEnv},
+		{"branch", environment.Event{Branch: "master"}, map[string]string{
+			"CIRRUS_BRANCH":         "master",
+			"CIRRUS_CHANGE_IN_REPO": "abcdef",
+		}},
+		{"tag", environment.Event{Tag: "v2.0.0"}, map[string]string{
+			"CIRRUS_TAG":            "v2.0.0",
+			"CIRRUS_CHANGE_IN_REPO": "abcdef",
+		}},
+		{"cron", environment.Event{Cron: "nightly"}, map[string]string{
+			"CIRRUS_BRANCH":         "feature",
+			"CIRRUS_CRON":           "nightly",
+			"CIRRUS_CHANGE_IN_REPO": "abcdef",
+		}},
+		{"pull request", environment.Event{PR: 42, BaseBranch: "main", PRLabels: []string{"bug", "ci"}},
+			map[string]string{
+				"CIRRUS_BRANCH":         "pull/42",
+				"CIRRUS_PR":             "42",
+				"CIRRUS_PR_DRAFT":       "false",
+				"CIRRUS_PR_LABELS":      "bug,ci",
+				"CIRRUS_BASE_BRANCH":    "main",
+				"CIRRUS_HEAD_BRANCH":    "feature",
+				"CIRRUS_CHANGE_IN_REPO": "abcdef",
+			}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, testCase.Event.Apply(projectEnv))
+		})
+	}
+}
+
+// TestEventValidate ensures that self-contradictory events are rejected.
+func TestEventValidate(t *testing.T) {
+	valid := []environment.Event{
+		{},
+		{Branch: "master"},
+		{Branch: "release", Tag: "v1.0.0"},
+		{PR: 1, BaseBranch: "master", PRDraft: true},
+	}
+
+	for _, event := range valid {
+		assert.NoError(t, event.Validate())
+	}
+
+	invalid := []environment.Event{
+		{PR: -1},
+		{Tag: "v1.0.0", PR: 1},
+		{Tag: "v1.0.0", Cron: "nightly"},
+		{BaseBranch: "master"},
+		{PRLabels: []string{"bug"}},
+	}
+
+	for _, event := range invalid {
+		assert.True(t, errors.Is(event.Validate(), environment.ErrInvalidEvent))
+	}
+}
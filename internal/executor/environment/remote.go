@@ -0,0 +1,99 @@
+package environment
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultRemote is the Git remote that describes the project's repository unless specified otherwise.
+const DefaultRemote = "origin"
+
+type remoteRepository struct {
+	cloneURL string
+	host     string
+	owner    string
+	name     string
+}
+
+// parseRemoteURL extracts the repository's host, owner and name from an HTTP(S), SSH (including
+// the SCP-like "git@host:owner/name" syntax) or Git protocol remote URL, which works for the GitHub,
+// GitLab (where the owner may include the subgroups), Bitbucket and the self-hosted Git servers.
+func parseRemoteURL(rawURL string) (*remoteRepository, bool) {
+	repo := &remoteRepository{
+		cloneURL: rawURL,
+	}
+
+	var repoPath string
+
+	if strings.Contains(rawURL, "://") {
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, false
+		}
+
+		switch parsedURL.Scheme {
+		case "http", "https":
+			// Don't leak the credentials into the tasks
+			parsedURL.User = nil
+			repo.cloneURL = parsedURL.String()
+		case "ssh", "git", "git+ssh", "ssh+git":
+		default:
+			return nil, false
+		}
+
+		repo.host = parsedURL.Hostname()
+		repoPath = parsedURL.Path
+	} else {
+		hostPart, pathPart, ok := splitSCPLikeURL(rawURL)
+		if !ok {
+			return nil, false
+		}
+
+		repo.host = hostPart
+		repoPath = pathPart
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	parts := strings.Split(repoPath, "/")
+
+	const minParts = 2
+	if repo.host == "" || len(parts) < minParts {
+		return nil, false
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return nil, false
+		}
+	}
+
+	repo.owner = strings.Join(parts[:len(parts)-1], "/")
+	repo.name = parts[len(parts)-1]
+
+	return repo, true
+}
+
+// splitSCPLikeURL splits the "[user@]host:path" URL into a host and path,
+// making sure that it's not a local path (e.g. "C:/repo" on Windows).
+func splitSCPLikeURL(rawURL string) (string, string, bool) {
+	colon := strings.Index(rawURL, ":")
+	if colon == -1 {
+		return "", "", false
+	}
+
+	host := rawURL[:colon]
+	if strings.Contains(host, "/") || strings.Contains(host, "\\") {
+		return "", "", false
+	}
+
+	if at := strings.LastIndex(host, "@"); at != -1 {
+		host = host[at+1:]
+	}
+
+	// Single letter is most likely a Windows drive
+	if len(host) <= 1 {
+		return "", "", false
+	}
+
+	return host, rawURL[colon+1:], true
+}
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/artifacts"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/build/taskstatus"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/cache"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/environment"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
@@ -14,19 +16,29 @@ import (
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/runconfig"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/options"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/rpc"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/secrets"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/taskfilter"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/tasklogs"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/cirruslabs/echelon"
 	"github.com/cirruslabs/echelon/renderers"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 )
 
 var ErrBuildFailed = errors.New("build failed")
 
 type Executor struct {
 	build *build.Build
-	rpc   *rpc.RPC
+
+	// RPC servers shared by all tasks in the build, keyed by their listen address
+	rpcs     map[string]*rpc.RPC
+	rpcsLock sync.Mutex
 
 	// Options
 	logger                   *echelon.Logger
@@ -36,6 +48,21 @@ type Executor struct {
 	dirtyMode                bool
 	containerBackend         containerbackend.ContainerBackend
 	containerOptions         options.ContainerOptions
+	parallelism              int
+	continueOnFailure        bool
+	retries                  int
+	debugOnFailure           bool
+	dryRun                   bool
+	artifacts                *artifacts.Store
+	taskLogs                 *tasklogs.Store
+	cacheDir                 string
+	cacheNamespaceOptions    cache.NamespaceOptions
+	cacheOptions             []cache.Option
+	remoteCache              cache.Cache
+	remoteCacheMode          string
+	secrets                  []string
+	secretEnv                []string
+	tracer                   *trace.Tracer
 }
 
 func New(projectDir string, tasks []*api.Task, opts ...Option) (*Executor, error) {
@@ -47,6 +74,8 @@ func New(projectDir string, tasks []*api.Task, opts ...Option) (*Executor, error
 			environment.ProjectSpecific(projectDir),
 		),
 		userSpecifiedEnvironment: make(map[string]string),
+		rpcs:                     make(map[string]*rpc.RPC),
+		parallelism:              1,
 	}
 
 	// Apply options
@@ -66,6 +95,9 @@ func New(projectDir string, tasks []*api.Task, opts ...Option) (*Executor, error
 		}
 		e.containerBackend = backend
 	}
+	if e.tracer != nil {
+		e.containerBackend = containerbackend.NewTracing(e.containerBackend)
+	}
 
 	// Filter tasks (e.g. if a user wants to run only a specific task without dependencies)
 	tasks, err := e.taskFilter(tasks)
@@ -91,8 +123,29 @@ func New(projectDir string, tasks []*api.Task, opts ...Option) (*Executor, error
 		)
 	}
 
+	// Collect the values to hide from the task logs
+	for _, task := range tasks {
+		e.secrets = append(e.secrets, secrets.Sensitive(task.Environment, e.secretEnv)...)
+	}
+
+	// Open the cache that will be used by the tasks
+	var c cache.Cache = cache.Disabled{}
+	if !e.dryRun {
+		namespace := cache.Namespace(projectDir, e.cacheNamespaceOptions)
+		c, err = cache.New(e.cacheDir, namespace, e.cacheOptions...)
+		if err != nil {
+			return nil, err
+		}
+		if e.remoteCache != nil {
+			c, err = cache.WithRemote(c, e.remoteCache, e.remoteCacheMode, e.logger)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Create a build that describes what we're about to do
-	b, err := build.New(projectDir, tasks, e.logger)
+	b, err := build.New(projectDir, tasks, e.logger, build.WithCache(c))
 	if err != nil {
 		return nil, err
 	}
@@ -128,16 +181,173 @@ func New(projectDir string, tasks []*api.Task, opts ...Option) (*Executor, error
 }
 
 func (e *Executor) Run(ctx context.Context) error {
+	defer e.stopRPCs()
+
+	ctx = trace.WithLane(trace.WithTracer(ctx, e.tracer), trace.BuildLane, "build")
+	ctx, span := trace.Start(ctx, "build")
+	defer span.End()
+
+	parallelism := e.parallelism
+	if parallelism <= 0 {
+		parallelism = e.detectParallelism(ctx)
+	}
+	e.logger.Debugf("running at most %d task(s) in parallel", parallelism)
+
+	// Cancel the tasks that are still running once any of the tasks fails
+	// (unless we're asked to continue on failure)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type taskResult struct {
+		task *build.Task
+		err  error
+	}
+
+	results := make(chan taskResult)
+	dispatched := make(map[int64]struct{})
+	var running, failures int
+	var firstErr error
+
 	for {
-		// Pick next undone task to run
-		task := e.build.GetNextTask()
-		if task == nil {
-			return nil
+		// Dispatch all undone tasks whose dependencies are satisfied
+		if firstErr == nil || e.continueOnFailure {
+			var skippedAny bool
+
+			for _, task := range e.build.GetReadyTasks() {
+				if e.build.HasFailedDependencies(task) {
+					e.skipTask(task)
+					skippedAny = true
+
+					continue
+				}
+
+				if running >= parallelism {
+					break
+				}
+
+				if _, ok := dispatched[task.ID]; ok {
+					continue
+				}
+				dispatched[task.ID] = struct{}{}
+				running++
+
+				go func(task *build.Task) {
+					results <- taskResult{task: task, err: e.runSingleTask(ctx, task)}
+				}(task)
+			}
+
+			// Skipping a task might have unblocked its dependents
+			if skippedAny {
+				continue
+			}
 		}
 
-		if err := e.runSingleTask(ctx, task); err != nil {
-			return err
+		// Nothing to run and nothing to wait for
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+
+		if result.err == nil {
+			continue
+		}
+
+		// Task didn't have a chance to set it's own status
+		if result.task.Status() == taskstatus.New {
+			if ctx.Err() != nil {
+				result.task.SetStatus(taskstatus.Aborted)
+			} else {
+				result.task.SetStatus(taskstatus.Failed)
+			}
+		}
+
+		if result.task.AllowFailures {
+			e.logger.Warnf("ignoring failure of task %s since it allows failures: %v", result.task.String(), result.err)
+
+			continue
+		}
+
+		failures++
+		if firstErr == nil {
+			firstErr = result.err
 		}
+
+		if !e.continueOnFailure {
+			cancel()
+		}
+	}
+
+	if failures > 1 && e.continueOnFailure {
+		return fmt.Errorf("%w: %d tasks have failed, the first failure was: %v", ErrBuildFailed, failures, firstErr)
+	}
+
+	return firstErr
+}
+
+func (e *Executor) skipTask(task *build.Task) {
+	task.SetStatus(taskstatus.Skipped)
+
+	e.logger.Debugf("task %s %s", task.String(), task.Status().String())
+	taskLogger := e.logger.Scoped(task.UniqueDescription())
+	taskLogger.Warnf("Skipping task since some of its dependencies have failed")
+	taskLogger.Finish(false)
+}
+
+// detectParallelism estimates how many tasks can be run concurrently
+// based on the resources available to the container backend daemon.
+func (e *Executor) detectParallelism(ctx context.Context) int {
+	const (
+		cpusPerTask   = 2
+		memoryPerTask = 4 * 1024 * 1024 * 1024
+	)
+
+	info, err := e.containerBackend.SystemInfo(ctx)
+	if err != nil {
+		e.logger.Debugf("failed to detect parallelism, falling back to running tasks serially: %v", err)
+
+		return 1
+	}
+
+	parallelism := info.TotalCPUs / cpusPerTask
+	if byMemory := info.TotalMemoryBytes / memoryPerTask; byMemory < parallelism {
+		parallelism = byMemory
+	}
+
+	if parallelism < 1 {
+		return 1
+	}
+
+	return int(parallelism)
+}
+
+// getRPC returns an RPC server listening on the specified address, starting it if necessary.
+func (e *Executor) getRPC(ctx context.Context, address string) (*rpc.RPC, error) {
+	e.rpcsLock.Lock()
+	defer e.rpcsLock.Unlock()
+
+	if r, ok := e.rpcs[address]; ok {
+		return r, nil
+	}
+
+	r := rpc.New(e.build, rpc.WithLogger(e.logger), rpc.WithTracer(e.tracer), rpc.WithArtifacts(e.artifacts),
+		rpc.WithSecrets(e.secrets), rpc.WithTaskLogs(e.taskLogs))
+	if err := r.Start(ctx, address); err != nil {
+		return nil, err
+	}
+	e.rpcs[address] = r
+
+	return r, nil
+}
+
+func (e *Executor) stopRPCs() {
+	e.rpcsLock.Lock()
+	defer e.rpcsLock.Unlock()
+
+	for address, r := range e.rpcs {
+		r.Stop()
+		delete(e.rpcs, address)
 	}
 }
 
@@ -155,48 +365,98 @@ func (e *Executor) runSingleTask(ctx context.Context, task *build.Task) error {
 		address = ip + ":0"
 	}
 
-	e.rpc = rpc.New(e.build, rpc.WithLogger(e.logger))
-	if err := e.rpc.Start(ctx, address); err != nil {
+	taskRPC, err := e.getRPC(ctx, address)
+	if err != nil {
 		return err
 	}
-	defer e.rpc.Stop()
 
 	e.logger.Debugf("running task %s", task.String())
+	task.MarkStarted()
+	defer task.MarkFinished()
+
+	ctx = trace.WithLane(ctx, trace.TaskLane(task.ID), task.String())
+	ctx, span := trace.Start(ctx, "task "+task.String())
+	defer span.End()
+	defer e.recordCommandSpans(task)
+
 	taskLogger := e.logger.Scoped(task.UniqueDescription())
 
 	// Prepare task's instance
 	instanceRunOpts := runconfig.RunConfig{
 		ContainerBackend:  e.containerBackend,
 		ProjectDir:        e.build.ProjectDir,
-		ContainerEndpoint: e.rpc.ContainerEndpoint(),
-		DirectEndpoint:    e.rpc.DirectEndpoint(),
-		ServerSecret:      e.rpc.ServerSecret(),
-		ClientSecret:      e.rpc.ClientSecret(),
+		ContainerEndpoint: taskRPC.ContainerEndpoint(),
+		DirectEndpoint:    taskRPC.DirectEndpoint(),
+		ServerSecret:      taskRPC.ServerSecret(),
+		ClientSecret:      taskRPC.ClientSecret(),
 		TaskID:            task.ID,
 		Logger:            taskLogger,
 		DirtyMode:         e.dirtyMode,
 		ContainerOptions:  e.containerOptions,
 	}
 
+	if e.debugOnFailure {
+		instanceRunOpts.DebugOnFailure = &runconfig.DebugOnFailure{
+			TaskFailed:  task.FailedAtLeastOnce,
+			Environment: task.Environment,
+		}
+	}
+
+	// Don't mix the logs and the artifacts with the ones left by the previous runs
+	if err := e.clearTaskOutputs(task); err != nil {
+		return err
+	}
+
+	// Expose the artifacts uploaded by the task's dependencies
+	if e.artifacts != nil {
+		instanceRunOpts.DependencyArtifacts, err = e.dependencyArtifacts(task)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Respect custom agent version
 	if agentVersionFromEnv, ok := task.Environment["CIRRUS_AGENT_VERSION"]; ok {
 		instanceRunOpts.SetAgentVersion(agentVersionFromEnv)
 	}
 
-	// Wrap the context to enforce a timeout for this task
-	ctx, cancel := context.WithTimeout(ctx, task.Timeout)
+	// Retry the task if it has failed for the reasons unrelated to its commands
+	maxRetries := e.retries
+	if task.AutoRetries > maxRetries {
+		maxRetries = task.AutoRetries
+	}
 
 	// Run task
 	var timedOut bool
-	if err := task.Instance.Run(ctx, &instanceRunOpts); err != nil {
-		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			timedOut = true
-		} else {
-			cancel()
+	for attempt := 0; ; attempt++ {
+		timedOut, err = e.runInstance(ctx, task, &instanceRunOpts)
+
+		infraErr := e.infrastructureFailure(ctx, task, timedOut, err)
+		if infraErr == nil || attempt >= maxRetries {
+			if err != nil {
+				return err
+			}
+
+			break
+		}
+
+		delay := retryDelay(attempt)
+		taskLogger.Warnf("Retrying in %v (attempt %d of %d) due to an infrastructure failure: %v",
+			delay, attempt+2, maxRetries+1, infraErr)
+
+		select {
+		case <-ctx.Done():
+			return infraErr
+		case <-time.After(delay):
+		}
+
+		task.ResetCommands()
+
+		// Discard the logs and the partially uploaded artifacts of the failed attempt
+		if err := e.clearTaskOutputs(task); err != nil {
 			return err
 		}
 	}
-	cancel()
 
 	// Handle timeout
 	if timedOut {
@@ -230,6 +490,142 @@ func (e *Executor) runSingleTask(ctx context.Context, task *build.Task) error {
 	return nil
 }
 
+// clearTaskOutputs removes the logs and the artifacts left by the task's previous runs.
+func (e *Executor) clearTaskOutputs(task *build.Task) error {
+	if e.taskLogs != nil {
+		if err := e.taskLogs.Clear(task.UniqueDescription()); err != nil {
+			return err
+		}
+	}
+
+	if e.artifacts != nil {
+		if err := e.artifacts.Clear(task.UniqueName()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dependencyArtifacts returns the artifacts uploaded by the task's dependencies.
+func (e *Executor) dependencyArtifacts(task *build.Task) (*runconfig.DependencyArtifacts, error) {
+	// Bind mounts require an absolute path
+	dir, err := filepath.Abs(e.artifacts.Dir())
+	if err != nil {
+		return nil, err
+	}
+
+	dependencyArtifacts := &runconfig.DependencyArtifacts{
+		Dir: dir,
+	}
+
+	for _, requiredID := range task.RequiredIDs {
+		taskDir := e.artifacts.TaskDirName(e.build.GetTask(requiredID).UniqueName())
+
+		// Skip the dependencies that haven't uploaded anything
+		if _, err := os.Stat(filepath.Join(dir, taskDir)); err != nil {
+			continue
+		}
+
+		dependencyArtifacts.TaskDirs = append(dependencyArtifacts.TaskDirs, taskDir)
+	}
+
+	return dependencyArtifacts, nil
+}
+
+// runInstance runs the task's instance once, only returning an error if the instance itself has failed.
+func (e *Executor) runInstance(
+	ctx context.Context,
+	task *build.Task,
+	instanceRunOpts *runconfig.RunConfig,
+) (bool, error) {
+	// Wrap the context to enforce a timeout for this task
+	ctx, cancel := context.WithTimeout(ctx, task.Timeout)
+	defer cancel()
+
+	if err := task.Instance.Run(ctx, instanceRunOpts); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return false, nil
+}
+
+// infrastructureFailure returns an error if the task's instance run has failed due to
+// the reasons unrelated to the task's commands (e.g. a failed image pull or an agent
+// that had died before connecting to us), which might be fixed by re-running the task.
+func (e *Executor) infrastructureFailure(ctx context.Context, task *build.Task, timedOut bool, err error) error {
+	// Cancellations, timeouts and failed commands are not something a retry would fix
+	if ctx.Err() != nil || timedOut || task.FailedAtLeastOnce() {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Prebuilt instance doesn't require any tasks to be run to be considered successful
+	if _, isPrebuilt := task.Instance.(*instance.PrebuiltInstance); isPrebuilt {
+		return nil
+	}
+
+	if task.Status() == taskstatus.New {
+		return fmt.Errorf("%w: instance terminated before the task %s had a chance to run",
+			ErrBuildFailed, task.String())
+	}
+
+	return nil
+}
+
+// retryDelay returns an exponentially increasing delay before the next attempt to run the task.
+func retryDelay(attempt int) time.Duration {
+	const (
+		initialDelay = time.Second
+		maxDelay     = 30 * time.Second
+	)
+
+	delay := initialDelay << attempt
+	if delay > maxDelay || delay <= 0 {
+		return maxDelay
+	}
+
+	return delay
+}
+
+// recordCommandSpans records the spans for the commands reported by the agent.
+func (e *Executor) recordCommandSpans(task *build.Task) {
+	if e.tracer == nil {
+		return
+	}
+
+	for _, command := range task.Commands {
+		startTime, finishTime := command.Timing()
+		if startTime.IsZero() {
+			continue
+		}
+		if finishTime.IsZero() {
+			finishTime = time.Now()
+		}
+
+		e.tracer.Record("command "+command.ProtoCommand.Name, trace.TaskLane(task.ID), startTime, finishTime,
+			map[string]string{"status": command.Status().String()})
+	}
+}
+
+// Build returns the build that this executor is running.
+func (e *Executor) Build() *build.Build {
+	return e.build
+}
+
+// Masker returns the masker that hides the secrets and the sensitive variable values
+// of the tasks the same way as in the task logs.
+func (e *Executor) Masker() *secrets.Masker {
+	return secrets.NewMasker(e.secrets)
+}
+
 func (e *Executor) transformDockerfileImageIfNeeded(reference string, strict bool) (string, error) {
 	// Modify image name if the user provided a custom template
 	if e.containerOptions.DockerfileImageTemplate == "" {
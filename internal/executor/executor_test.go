@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/cirruslabs/cirrus-ci-agent/api"
 	"github.com/cirruslabs/cirrus-cli/internal/executor"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
 	"github.com/cirruslabs/cirrus-cli/internal/testutil"
@@ -194,6 +195,80 @@ func TestCache(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestArtifacts ensures that the artifacts uploaded by the task are stored in the artifacts directory.
+func TestArtifacts(t *testing.T) {
+	dir := testutil.TempDirPopulatedWith(t, "testdata/artifacts")
+	artifactsDir := filepath.Join(dir, ".cirrus-artifacts")
+
+	renderer := renderers.NewSimpleRenderer(os.Stdout, nil)
+	logger := echelon.NewLogger(echelon.TraceLevel, renderer)
+
+	err := testutil.ExecuteWithOptions(t, dir, executor.WithLogger(logger), executor.WithArtifactsDir(artifactsDir))
+	require.NoError(t, err)
+
+	app, err := ioutil.ReadFile(filepath.Join(artifactsDir, "build", "binaries", "dist", "app"))
+	require.NoError(t, err)
+	assert.Equal(t, "binary\n", string(app))
+
+	data, err := ioutil.ReadFile(filepath.Join(artifactsDir, "build", "binaries", "dist", "nested", "data.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "nested\n", string(data))
+
+	assert.FileExists(t, filepath.Join(artifactsDir, "build", "binaries.metadata.json"))
+}
+
+// TestArtifactsMatrix ensures that the tasks generated by a matrix, which share the same name,
+// don't overwrite each other's artifacts.
+func TestArtifactsMatrix(t *testing.T) {
+	dir := testutil.TempDirPopulatedWith(t, "testdata/artifacts-matrix")
+	artifactsDir := filepath.Join(dir, ".cirrus-artifacts")
+
+	renderer := renderers.NewSimpleRenderer(os.Stdout, nil)
+	logger := echelon.NewLogger(echelon.TraceLevel, renderer)
+
+	err := testutil.ExecuteWithOptions(t, dir, executor.WithLogger(logger), executor.WithArtifactsDir(artifactsDir))
+	require.NoError(t, err)
+
+	versionFiles, err := filepath.Glob(filepath.Join(artifactsDir, "build*", "binaries", "dist", "version"))
+	require.NoError(t, err)
+	require.Len(t, versionFiles, 2)
+
+	var versions []string
+
+	for _, versionFile := range versionFiles {
+		version, err := ioutil.ReadFile(versionFile)
+		require.NoError(t, err)
+		versions = append(versions, string(version))
+	}
+
+	assert.ElementsMatch(t, []string{"1\n", "2\n"}, versions)
+}
+
+// TestDependencyArtifacts ensures that the artifacts uploaded by the task are available to its dependents.
+func TestDependencyArtifacts(t *testing.T) {
+	dir := testutil.TempDirPopulatedWith(t, "testdata/dependency-artifacts")
+
+	renderer := renderers.NewSimpleRenderer(os.Stdout, nil)
+	logger := echelon.NewLogger(echelon.TraceLevel, renderer)
+
+	err := testutil.ExecuteWithOptions(t, dir, executor.WithLogger(logger),
+		executor.WithArtifactsDir(filepath.Join(dir, ".cirrus-artifacts")))
+	require.NoError(t, err)
+}
+
+// TestDependencyArtifactsMatrix ensures that the dependent task sees the artifacts of all the tasks
+// generated by the matrix it depends on, even when these tasks run in parallel.
+func TestDependencyArtifactsMatrix(t *testing.T) {
+	dir := testutil.TempDirPopulatedWith(t, "testdata/dependency-artifacts-matrix")
+
+	renderer := renderers.NewSimpleRenderer(os.Stdout, nil)
+	logger := echelon.NewLogger(echelon.TraceLevel, renderer)
+
+	err := testutil.ExecuteWithOptions(t, dir, executor.WithLogger(logger), executor.WithParallelism(2),
+		executor.WithArtifactsDir(filepath.Join(dir, ".cirrus-artifacts")))
+	require.NoError(t, err)
+}
+
 // Check that override ENTRYPOINT.
 func TestEntrypoint(t *testing.T) {
 	dir := testutil.TempDirPopulatedWith(t, "testdata/entrypoint")
@@ -371,6 +446,19 @@ func TestPersistentWorker(t *testing.T) {
 	assert.Contains(t, buf.String(), "'check' script succeeded")
 }
 
+// TestPersistentWorkerDependencyArtifacts ensures that only the artifacts uploaded by the task's
+// dependencies are available to the task running on a persistent worker.
+func TestPersistentWorkerDependencyArtifacts(t *testing.T) {
+	dir := testutil.TempDirPopulatedWith(t, "testdata/persistent-worker-dependency-artifacts")
+
+	renderer := renderers.NewSimpleRenderer(os.Stdout, nil)
+	logger := echelon.NewLogger(echelon.TraceLevel, renderer)
+
+	err := testutil.ExecuteWithOptionsNew(t, dir, executor.WithLogger(logger),
+		executor.WithArtifactsDir(filepath.Join(dir, ".cirrus-artifacts")))
+	require.NoError(t, err)
+}
+
 // TestCirrusWorkingDir ensures that CIRRUS_WORKING_DIR environment variable is respected.
 func TestCirrusWorkingDir(t *testing.T) {
 	// Create a logger and attach it to writer
@@ -441,3 +529,99 @@ func TestContainerLogs(t *testing.T) {
 			" Background commands to clean up after: [0-9]+", buf.String())
 	}
 }
+
+// runRetriesTask runs a single task with the specified image, script and metadata properties
+// and returns the resulting logs along with the execution error.
+func runRetriesTask(
+	t *testing.T,
+	image string,
+	script string,
+	properties map[string]string,
+	opts ...executor.Option,
+) (string, error) {
+	// Create os.Stderr writer that duplicates it's output to buf
+	buf := bytes.NewBufferString("")
+	writer := io.MultiWriter(os.Stderr, buf)
+
+	// Create a logger and attach it to writer
+	renderer := renderers.NewSimpleRenderer(writer, nil)
+	logger := echelon.NewLogger(echelon.TraceLevel, renderer)
+
+	opts = append(opts, executor.WithLogger(logger), executor.WithContainerBackend(testutil.ContainerBackendFromEnv(t)))
+
+	e, err := executor.New(testutil.TempDir(t), []*api.Task{
+		{
+			LocalGroupId: 0,
+			Name:         "main",
+			Commands: []*api.Command{
+				{
+					Name: "main",
+					Instruction: &api.Command_ScriptInstruction{
+						ScriptInstruction: &api.ScriptInstruction{
+							Scripts: []string{script},
+						},
+					},
+				},
+			},
+			Metadata: &api.Task_Metadata{
+				Properties: properties,
+			},
+			Instance: testutil.GetBasicContainerInstance(t, image),
+		},
+	}, opts...)
+	require.NoError(t, err)
+
+	err = e.Run(context.Background())
+
+	return buf.String(), err
+}
+
+// nonExistentImage causes an instance failure before the task has a chance to run.
+const nonExistentImage = "cirrus-cli-test/non-existent-image:latest"
+
+// TestRetriesInstanceFailure ensures that the task is retried when its instance fails.
+func TestRetriesInstanceFailure(t *testing.T) {
+	logs, err := runRetriesTask(t, nonExistentImage, "true", nil, executor.WithRetries(1))
+	assert.Error(t, err)
+	assert.Contains(t, logs, "attempt 2 of 2")
+	assert.NotContains(t, logs, "attempt 3")
+}
+
+// TestRetriesCommandFailure ensures that the failed commands and timeouts are not retried,
+// since running the task again won't fix them.
+func TestRetriesCommandFailure(t *testing.T) {
+	logs, err := runRetriesTask(t, "debian:latest", "false", nil, executor.WithRetries(2))
+	assert.True(t, errors.Is(err, executor.ErrBuildFailed))
+	assert.NotContains(t, logs, "Retrying")
+
+	logs, err = runRetriesTask(t, "debian:latest", "sleep 60", map[string]string{"timeout_in": "5"},
+		executor.WithRetries(2))
+	require.True(t, errors.Is(err, executor.ErrBuildFailed))
+	assert.Contains(t, err.Error(), "timed out")
+	assert.NotContains(t, logs, "Retrying")
+}
+
+// TestRetriesAutoRetry ensures that the larger of --retries and auto_retry is used.
+func TestRetriesAutoRetry(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Retries   int
+		AutoRetry string
+	}{
+		{"auto_retry is larger", 1, "2"},
+		{"--retries is larger", 2, "1"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+
+		t.Run(testCase.Name, func(t *testing.T) {
+			logs, err := runRetriesTask(t, nonExistentImage, "true",
+				map[string]string{build.AutoRetryProperty: testCase.AutoRetry},
+				executor.WithRetries(testCase.Retries))
+			assert.Error(t, err)
+			assert.Contains(t, logs, "attempt 3 of 3")
+			assert.NotContains(t, logs, "attempt 4")
+		})
+	}
+}
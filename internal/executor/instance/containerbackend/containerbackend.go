@@ -37,6 +37,7 @@ type ContainerBackend interface {
 	ContainerWait(ctx context.Context, id string) (<-chan ContainerWaitResult, <-chan error)
 	ContainerLogs(ctx context.Context, id string) (<-chan string, error)
 	ContainerDelete(ctx context.Context, id string) error
+	ContainerExec(ctx context.Context, id string, input *ContainerExecInput) error
 
 	SystemInfo(ctx context.Context) (*SystemInfo, error)
 }
@@ -59,6 +60,19 @@ type ContainerCreateInput struct {
 	DisableSELinux bool
 }
 
+// ContainerExecInput describes a command to run in an already running container. Command's
+// standard input is streamed from Stdin (if any), output is streamed into Stdout and Stderr,
+// except when TTY is set, in which case all the output is streamed into Stdout.
+type ContainerExecInput struct {
+	Command    []string
+	Env        map[string]string
+	WorkingDir string
+	TTY        bool
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+}
+
 type ContainerMountType int
 
 const (
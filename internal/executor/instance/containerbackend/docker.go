@@ -215,9 +215,10 @@ func (backend *Docker) ContainerCreate(
 		}
 
 		newMount := mount.Mount{
-			Type:   dockerType,
-			Source: ourMount.Source,
-			Target: ourMount.Target,
+			Type:     dockerType,
+			Source:   ourMount.Source,
+			Target:   ourMount.Target,
+			ReadOnly: ourMount.ReadOnly,
 		}
 
 		hostConfig.Mounts = append(hostConfig.Mounts, newMount)
@@ -307,6 +308,31 @@ func (backend *Docker) ContainerDelete(ctx context.Context, id string) error {
 	})
 }
 
+func (backend *Docker) ContainerExec(ctx context.Context, id string, input *ContainerExecInput) error {
+	exec, err := backend.cli.ContainerExecCreate(ctx, id, types.ExecConfig{
+		Tty:          input.TTY,
+		AttachStdin:  input.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          envMapToSlice(input.Env),
+		WorkingDir:   input.WorkingDir,
+		Cmd:          input.Command,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := backend.cli.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{
+		Tty: input.TTY,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+
+	return attachExec(resp.Conn, resp.CloseWrite, resp.Reader, input)
+}
+
 func (backend *Docker) SystemInfo(ctx context.Context) (*SystemInfo, error) {
 	info, err := backend.cli.Info(ctx)
 	if err != nil {
@@ -0,0 +1,28 @@
+package containerbackend
+
+import (
+	"github.com/docker/docker/pkg/stdcopy"
+	"io"
+)
+
+// attachExec streams the input's standard input into the exec session's connection
+// and the session's output into the input's standard output and error until the session ends.
+func attachExec(conn io.Writer, closeWrite func() error, output io.Reader, input *ContainerExecInput) error {
+	if input.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(conn, input.Stdin)
+			_ = closeWrite()
+		}()
+	}
+
+	// Without TTY the standard output and error are multiplexed into a single stream
+	if input.TTY {
+		_, err := io.Copy(input.Stdout, output)
+
+		return err
+	}
+
+	_, err := stdcopy.StdCopy(input.Stdout, input.Stderr, output)
+
+	return err
+}
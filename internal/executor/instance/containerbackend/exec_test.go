@@ -0,0 +1,47 @@
+package containerbackend_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// TestContainerExec ensures that the commands can be executed in a running container
+// with the specified environment and working directory.
+func TestContainerExec(t *testing.T) {
+	ctx := context.Background()
+	backend := testutil.ContainerBackendFromEnv(t)
+
+	const image = "debian:latest"
+	require.NoError(t, backend.ImagePull(ctx, image))
+
+	cont, err := backend.ContainerCreate(ctx, &containerbackend.ContainerCreateInput{
+		Image:      image,
+		Entrypoint: []string{"sleep", "600"},
+	}, "")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, backend.ContainerDelete(context.Background(), cont.ID))
+	}()
+
+	require.NoError(t, backend.ContainerStart(ctx, cont.ID))
+
+	var stdout, stderr bytes.Buffer
+
+	err = backend.ContainerExec(ctx, cont.ID, &containerbackend.ContainerExecInput{
+		Command:    []string{"/bin/sh", "-c", "echo $GREETING; pwd; read input; echo $input >&2"},
+		Env:        map[string]string{"GREETING": "Hello!"},
+		WorkingDir: "/tmp",
+		Stdin:      strings.NewReader("from stdin\n"),
+		Stdout:     &stdout,
+		Stderr:     &stderr,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "Hello!\n/tmp\n", stdout.String())
+	require.Equal(t, "from stdin\n", stderr.String())
+}
@@ -2,6 +2,7 @@ package containerbackend
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -28,6 +29,7 @@ var ErrPodman = errors.New("Podman error")
 
 type Podman struct {
 	cmd        *exec.Cmd
+	socketPath string
 	basePath   string
 	httpClient *http.Client
 	cli        *swagger.APIClient
@@ -59,8 +61,9 @@ func NewPodman() (ContainerBackend, error) {
 	}
 
 	podman := &Podman{
-		cmd:      cmd,
-		basePath: "http://d/v1.0.0",
+		cmd:        cmd,
+		socketPath: socketPath,
+		basePath:   "http://d/v1.0.0",
 		httpClient: &http.Client{
 			Transport: &http.Transport{
 				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
@@ -530,6 +533,100 @@ func (backend *Podman) ContainerDelete(ctx context.Context, id string) error {
 	return err
 }
 
+func (backend *Podman) ContainerExec(ctx context.Context, id string, input *ContainerExecInput) error {
+	// Create an exec session
+	createBody, err := json.Marshal(&struct {
+		Cmd          []string
+		Env          []string
+		WorkingDir   string
+		Tty          bool
+		AttachStdin  bool
+		AttachStdout bool
+		AttachStderr bool
+	}{
+		Cmd:          input.Command,
+		Env:          envMapToSlice(input.Env),
+		WorkingDir:   input.WorkingDir,
+		Tty:          input.TTY,
+		AttachStdin:  input.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", backend.basePath+"/libpod/containers/"+id+"/exec",
+		bytes.NewReader(createBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := backend.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%w: exec create endpoint returned HTTP %d", ErrPodman, resp.StatusCode)
+	}
+
+	var session struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return err
+	}
+
+	// Start the exec session over a separate connection, which we'll hijack
+	// after receiving the response headers since net/http doesn't support
+	// bidirectional streaming
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", backend.socketPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	startBody, err := json.Marshal(&struct {
+		Detach bool
+		Tty    bool
+	}{
+		Tty: input.TTY,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err = http.NewRequestWithContext(ctx, "POST", backend.basePath+"/libpod/exec/"+session.ID+"/start",
+		bytes.NewReader(startBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	connReader := bufio.NewReader(conn)
+
+	// nolint:bodyclose // the body is the hijacked connection, which is closed above
+	startResp, err := http.ReadResponse(connReader, req)
+	if err != nil {
+		return err
+	}
+
+	if startResp.StatusCode != http.StatusOK && startResp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("%w: exec start endpoint returned HTTP %d", ErrPodman, startResp.StatusCode)
+	}
+
+	return attachExec(conn, conn.(*net.UnixConn).CloseWrite, connReader, input)
+}
+
 func (backend *Podman) SystemInfo(ctx context.Context) (*SystemInfo, error) {
 	// nolint:bodyclose // already closed by Swagger-generated code
 	info, _, err := backend.cli.SystemApi.LibpodGetInfo(ctx)
@@ -0,0 +1,76 @@
+package containerbackend
+
+import (
+	"context"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
+)
+
+// Tracing is a container backend wrapper that records the spans for the container
+// backend operations into the tracer carried by the context (if any).
+type Tracing struct {
+	ContainerBackend
+}
+
+func NewTracing(backend ContainerBackend) *Tracing {
+	return &Tracing{ContainerBackend: backend}
+}
+
+func (backend *Tracing) ImagePull(ctx context.Context, reference string) error {
+	ctx, span := trace.Start(ctx, "image pull")
+	span.SetAttribute("image", reference)
+	defer span.End()
+
+	return backend.ContainerBackend.ImagePull(ctx, reference)
+}
+
+func (backend *Tracing) ImagePush(ctx context.Context, reference string) error {
+	ctx, span := trace.Start(ctx, "image push")
+	span.SetAttribute("image", reference)
+	defer span.End()
+
+	return backend.ContainerBackend.ImagePush(ctx, reference)
+}
+
+func (backend *Tracing) VolumeCreate(ctx context.Context, name string) error {
+	ctx, span := trace.Start(ctx, "volume create")
+	span.SetAttribute("volume", name)
+	defer span.End()
+
+	return backend.ContainerBackend.VolumeCreate(ctx, name)
+}
+
+func (backend *Tracing) VolumeDelete(ctx context.Context, name string) error {
+	ctx, span := trace.Start(ctx, "volume delete")
+	span.SetAttribute("volume", name)
+	defer span.End()
+
+	return backend.ContainerBackend.VolumeDelete(ctx, name)
+}
+
+func (backend *Tracing) ContainerCreate(
+	ctx context.Context,
+	input *ContainerCreateInput,
+	name string,
+) (*ContainerCreateOutput, error) {
+	ctx, span := trace.Start(ctx, "container create")
+	span.SetAttribute("image", input.Image)
+	defer span.End()
+
+	return backend.ContainerBackend.ContainerCreate(ctx, input, name)
+}
+
+func (backend *Tracing) ContainerStart(ctx context.Context, id string) error {
+	ctx, span := trace.Start(ctx, "container start")
+	span.SetAttribute("container", id)
+	defer span.End()
+
+	return backend.ContainerBackend.ContainerStart(ctx, id)
+}
+
+func (backend *Tracing) ContainerDelete(ctx context.Context, id string) error {
+	ctx, span := trace.Start(ctx, "container delete")
+	span.SetAttribute("container", id)
+	defer span.End()
+
+	return backend.ContainerBackend.ContainerDelete(ctx, id)
+}
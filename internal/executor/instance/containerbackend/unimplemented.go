@@ -73,6 +73,10 @@ func (*Unimplemented) ContainerLogs(ctx context.Context, id string) (<-chan stri
 
 func (*Unimplemented) ContainerDelete(ctx context.Context, id string) error { return ErrNotImplemented }
 
+func (*Unimplemented) ContainerExec(ctx context.Context, id string, input *ContainerExecInput) error {
+	return ErrNotImplemented
+}
+
 func (*Unimplemented) SystemInfo(ctx context.Context) (*SystemInfo, error) {
 	return nil, ErrNotImplemented
 }
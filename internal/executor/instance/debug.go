@@ -0,0 +1,72 @@
+package instance
+
+import (
+	"context"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/runconfig"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/platform"
+	"github.com/moby/term"
+	"os"
+	"sync"
+)
+
+// agentFinishedMarker is printed by the container held for debugging once the agent has finished.
+const agentFinishedMarker = "CIRRUS_CLI_AGENT_FINISHED"
+
+// Only one debug shell can be attached to the terminal at a time.
+var debugShellLock sync.Mutex
+
+func debugShellSupported(params *Params) bool {
+	_, ok := params.Platform.(*platform.UnixPlatform)
+
+	return ok
+}
+
+// holdingEntrypoint wraps the agent's entrypoint so that the container keeps running after the agent
+// has finished, preserving the state left by the failed command for the debug shell.
+func holdingEntrypoint(agentEntrypoint []string) []string {
+	script := "\"$@\"; echo " + agentFinishedMarker + "; while true; do sleep 3600; done"
+
+	return append([]string{"/bin/sh", "-c", script, "sh"}, agentEntrypoint...)
+}
+
+// runDebugShell attaches an interactive shell to the failed task's container, which is still
+// running along with its additional containers, so that the user can poke around the state
+// left by the task.
+func runDebugShell(config *runconfig.RunConfig, params *Params, containerID string) error {
+	logger := config.Logger
+
+	if !debugShellSupported(params) {
+		logger.Warnf("not spawning a debug shell since it's only supported for Linux containers")
+
+		return nil
+	}
+
+	debugShellLock.Lock()
+	defer debugShellLock.Unlock()
+
+	// Switch the terminal into a raw mode (if any) to let the shell handle the input
+	stdinFd, isTerminal := term.GetFdInfo(os.Stdin)
+	if isTerminal {
+		state, err := term.SetRawTerminal(stdinFd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = term.RestoreTerminal(stdinFd, state)
+		}()
+	}
+
+	logger.Infof("Task has failed, attaching a debug shell in %s, exit it to continue...", params.WorkingDirectory)
+
+	// Use a separate context because the debug session is not bound by the task's timeout
+	return config.ContainerBackend.ContainerExec(context.Background(), containerID, &containerbackend.ContainerExecInput{
+		Command:    []string{"/bin/sh", "-c", "if command -v bash > /dev/null; then exec bash; else exec sh; fi"},
+		Env:        config.DebugOnFailure.Environment,
+		WorkingDir: params.WorkingDirectory,
+		TTY:        isTerminal,
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+	})
+}
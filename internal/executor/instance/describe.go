@@ -0,0 +1,43 @@
+package instance
+
+import (
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/abstract"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
+)
+
+// Images returns the container images that the instance will run on, if any.
+func Images(inst abstract.Instance) []string {
+	switch typedInst := inst.(type) {
+	case *ContainerInstance:
+		return []string{typedInst.Image}
+	case *PrebuiltInstance:
+		return []string{typedInst.Image}
+	case *PipeInstance:
+		var result []string
+		for _, stage := range typedInst.Stages {
+			result = append(result, stage.Image)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// Resources returns the CPU and memory (in MiB) requested by the instance,
+// or false if the instance doesn't limit its resources.
+func Resources(inst abstract.Instance) (float32, uint32, bool) {
+	switch typedInst := inst.(type) {
+	case *ContainerInstance:
+		return typedInst.CPU, typedInst.Memory, true
+	case *PipeInstance:
+		return typedInst.CPU, typedInst.Memory, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// ClampResources limits the requested CPU and memory (in MiB) to those available
+// for the container backend daemon.
+func ClampResources(cpu float32, memory uint32, info *containerbackend.SystemInfo) (float32, uint32) {
+	return clampCPU(cpu, float32(info.TotalCPUs)), clampMemory(memory, uint32(info.TotalMemoryBytes/mebi))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/runconfig"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/options"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/platform"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/cirruslabs/cirrus-cli/internal/logger"
 	"github.com/cirruslabs/echelon"
 	"github.com/golang/protobuf/ptypes"
@@ -137,19 +138,20 @@ func RunContainerizedAgent(ctx context.Context, config *runconfig.RunConfig, par
 	logger := config.Logger
 	backend := config.ContainerBackend
 
+	ctx, span := trace.Start(ctx, "run agent container")
+	span.SetAttribute("image", params.Image)
+	defer span.End()
+
 	// Clamp resources to those available for container backend daemon
 	info, err := backend.SystemInfo(ctx)
 	if err != nil {
 		return err
 	}
-	availableCPU := float32(info.TotalCPUs)
-	availableMemory := uint32(info.TotalMemoryBytes / mebi)
 
-	params.CPU = clampCPU(params.CPU, availableCPU)
-	params.Memory = clampMemory(params.Memory, availableMemory)
+	params.CPU, params.Memory = ClampResources(params.CPU, params.Memory, info)
 	for _, additionalContainer := range params.AdditionalContainers {
-		additionalContainer.Cpu = clampCPU(additionalContainer.Cpu, availableCPU)
-		additionalContainer.Memory = clampMemory(additionalContainer.Memory, availableMemory)
+		additionalContainer.Cpu, additionalContainer.Memory = ClampResources(additionalContainer.Cpu,
+			additionalContainer.Memory, info)
 	}
 
 	if err := pullHelper(ctx, params.Image, backend, config.ContainerOptions, logger); err != nil {
@@ -234,6 +236,20 @@ func RunContainerizedAgent(ctx context.Context, config *runconfig.RunConfig, par
 		})
 	}
 
+	// Expose the artifacts uploaded by the task's dependencies
+	if dependencyArtifacts := config.DependencyArtifacts; dependencyArtifacts != nil {
+		for _, taskDir := range dependencyArtifacts.TaskDirs {
+			input.Mounts = append(input.Mounts, containerbackend.ContainerMount{
+				Type:     containerbackend.MountTypeBind,
+				Source:   filepath.Join(dependencyArtifacts.Dir, taskDir),
+				Target:   path.Join(params.Platform.DependencyArtifactsDir(), taskDir),
+				ReadOnly: true,
+			})
+		}
+
+		input.Env[runconfig.DependencyArtifactsEnvVariable] = params.Platform.DependencyArtifactsDir()
+	}
+
 	// In case the additional containers are used, tell the agent to wait for them
 	if len(params.AdditionalContainers) > 0 {
 		var ports []string
@@ -246,6 +262,14 @@ func RunContainerizedAgent(ctx context.Context, config *runconfig.RunConfig, par
 		input.Env["CIRRUS_PORTS_WAIT_FOR"] = commaDelimitedPorts
 	}
 
+	// Keep the container running after the agent has finished in case the task fails
+	// and the debug shell needs to be attached to it, detecting the agent's completion
+	// from the container's output instead
+	holdContainer := config.DebugOnFailure != nil && debugShellSupported(params)
+	if holdContainer {
+		input.Entrypoint = holdingEntrypoint(input.Entrypoint)
+	}
+
 	cont, err := backend.ContainerCreate(ctx, &input, "")
 	if err != nil {
 		return err
@@ -318,8 +342,18 @@ func RunContainerizedAgent(ctx context.Context, config *runconfig.RunConfig, par
 	if err != nil {
 		return err
 	}
+	agentFinishedChan := make(chan struct{})
 	go func() {
+		agentFinished := false
+
 		for logLine := range logChan {
+			if holdContainer && !agentFinished && logLine == agentFinishedMarker {
+				close(agentFinishedChan)
+				agentFinished = true
+
+				continue
+			}
+
 			logger.Debugf("container: %s", logLine)
 		}
 		logReaderWg.Done()
@@ -330,12 +364,18 @@ func RunContainerizedAgent(ctx context.Context, config *runconfig.RunConfig, par
 	select {
 	case res := <-waitChan:
 		logger.Debugf("container exited with %v error and exit code %d", res.Error, res.StatusCode)
+	case <-agentFinishedChan:
+		logger.Debugf("agent has finished in container %s", cont.ID)
 	case err := <-errChan:
 		return err
 	case acErr := <-additionalContainersErrChan:
 		return acErr
 	}
 
+	if config.DebugOnFailure != nil && config.DebugOnFailure.TaskFailed() {
+		return runDebugShell(config, params, cont.ID)
+	}
+
 	return nil
 }
 
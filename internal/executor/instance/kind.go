@@ -0,0 +1,34 @@
+package instance
+
+import (
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/abstract"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/persistentworker/isolation/none"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/persistentworker/isolation/parallels"
+)
+
+const (
+	KindContainer                 = "container"
+	KindPipe                      = "pipe"
+	KindPrebuilt                  = "prebuilt"
+	KindPersistentWorker          = "persistent_worker"
+	KindPersistentWorkerParallels = "persistent_worker_parallels"
+	KindUnknown                   = "unknown"
+)
+
+// Kind returns a short machine-friendly name of the instance type.
+func Kind(inst abstract.Instance) string {
+	switch inst.(type) {
+	case *ContainerInstance:
+		return KindContainer
+	case *PipeInstance:
+		return KindPipe
+	case *PrebuiltInstance:
+		return KindPrebuilt
+	case *none.PersistentWorkerInstance:
+		return KindPersistentWorker
+	case *parallels.Parallels:
+		return KindPersistentWorkerParallels
+	default:
+		return KindUnknown
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/agent"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/runconfig"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/otiai10/copy"
 	"io/ioutil"
 	"os"
@@ -69,15 +70,33 @@ func (pwi *PersistentWorkerInstance) Run(ctx context.Context, config *runconfig.
 		pwi.tempDir,
 	)
 
+	// Expose the artifacts uploaded by the task's dependencies
+	if config.DependencyArtifacts != nil {
+		dependencyArtifactsDir, err := populateDependencyArtifacts(config.DependencyArtifacts)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = os.RemoveAll(dependencyArtifactsDir)
+		}()
+
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", runconfig.DependencyArtifactsEnvVariable,
+			dependencyArtifactsDir))
+	}
+
 	// Determine the working directory for the agent
 	if config.DirtyMode {
 		cmd.Dir = config.ProjectDir
 	} else {
 		// Populate the working directory
 		if config.ProjectDir != "" {
-			if err := copy.Copy(config.ProjectDir, pwi.tempDir); err != nil {
+			_, copySpan := trace.Start(ctx, "copy project directory")
+			copyErr := copy.Copy(config.ProjectDir, pwi.tempDir)
+			copySpan.End()
+
+			if copyErr != nil {
 				return fmt.Errorf("%w: while copying %s's contents into %s: %v",
-					ErrPopulateFailed, config.ProjectDir, pwi.tempDir, err)
+					ErrPopulateFailed, config.ProjectDir, pwi.tempDir, copyErr)
 			}
 		}
 
@@ -85,9 +104,31 @@ func (pwi *PersistentWorkerInstance) Run(ctx context.Context, config *runconfig.
 	}
 
 	// Run the agent
+	_, span := trace.Start(ctx, "run agent")
+	defer span.End()
+
 	return cmd.Run()
 }
 
+// populateDependencyArtifacts creates a temporary directory with the copies of the dependencies' artifacts only,
+// which is similar to the bind mounts used for the containers.
+func populateDependencyArtifacts(dependencyArtifacts *runconfig.DependencyArtifacts) (string, error) {
+	dir, err := ioutil.TempDir("", "cirrus-dependency-artifacts-")
+	if err != nil {
+		return "", err
+	}
+
+	for _, taskDir := range dependencyArtifacts.TaskDirs {
+		if err := copy.Copy(filepath.Join(dependencyArtifacts.Dir, taskDir), filepath.Join(dir, taskDir)); err != nil {
+			_ = os.RemoveAll(dir)
+
+			return "", fmt.Errorf("%w: while copying the artifacts of the dependencies: %v", ErrPopulateFailed, err)
+		}
+	}
+
+	return dir, nil
+}
+
 func (pwi *PersistentWorkerInstance) WorkingDirectory(projectDir string, dirtyMode bool) string {
 	if dirtyMode {
 		return projectDir
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/runconfig"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"io"
 	"io/ioutil"
 	"os"
@@ -121,6 +122,10 @@ func (prebuilt *PrebuiltInstance) Run(ctx context.Context, config *runconfig.Run
 	}()
 
 	// Build the image
+	_, span := trace.Start(ctx, "image build")
+	span.SetAttribute("image", prebuilt.Image)
+	defer span.End()
+
 	logChan, errChan := backend.ImageBuild(ctx, file, &containerbackend.ImageBuildInput{
 		Tags:       []string{prebuilt.Image},
 		Dockerfile: prebuilt.Dockerfile,
@@ -18,9 +18,33 @@ type RunConfig struct {
 	Logger                     *echelon.Logger
 	DirtyMode                  bool
 	ContainerOptions           options.ContainerOptions
+	DebugOnFailure             *DebugOnFailure
+	DependencyArtifacts        *DependencyArtifacts
 	agentVersion               string
 }
 
+// DebugOnFailure enables an interactive shell to be spawned in the task's container
+// after the task has failed.
+type DebugOnFailure struct {
+	// TaskFailed reports whether the task has failed and needs to be debugged.
+	TaskFailed func() bool
+
+	// Environment is set for the debug shell.
+	Environment map[string]string
+}
+
+// DependencyArtifactsEnvVariable points to a directory with the artifacts uploaded by the task's dependencies.
+const DependencyArtifactsEnvVariable = "CIRRUS_DEPENDENCY_ARTIFACTS_DIR"
+
+// DependencyArtifacts describes the artifacts uploaded by the tasks that the current task depends on.
+type DependencyArtifacts struct {
+	// Dir is a host directory with the artifacts of all tasks in the build.
+	Dir string
+
+	// TaskDirs are the names of the Dir's subdirectories that belong to the task's dependencies.
+	TaskDirs []string
+}
+
 func (rc *RunConfig) GetAgentVersion() string {
 	if rc.agentVersion == "" {
 		return platform.DefaultAgentVersion
@@ -8,6 +8,7 @@ import (
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/runconfig"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/options"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/platform"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/google/uuid"
 	"runtime"
 )
@@ -27,6 +28,9 @@ func CreateWorkingVolumeFromConfig(
 	config *runconfig.RunConfig,
 	platform platform.Platform,
 ) (*Volume, *Volume, error) {
+	ctx, span := trace.Start(ctx, "prepare working volume")
+	defer span.End()
+
 	initLogger := config.Logger.Scoped("Preparing execution environment...")
 	initLogger.Infof("Preparing volume to work with...")
 
@@ -135,6 +139,9 @@ func CreateWorkingVolume(
 	}
 
 	// Wait for the container to finish copying
+	_, copySpan := trace.Start(ctx, "copy project directory")
+	defer copySpan.End()
+
 	waitChan, errChan := backend.ContainerWait(ctx, cont.ID)
 	select {
 	case res := <-waitChan:
@@ -1,9 +1,13 @@
 package executor
 
 import (
+	"github.com/cirruslabs/cirrus-cli/internal/executor/artifacts"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/cache"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/options"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/taskfilter"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/tasklogs"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/cirruslabs/echelon"
 )
 
@@ -50,3 +54,110 @@ func WithContainerBackend(containerBackend containerbackend.ContainerBackend) Op
 		e.containerBackend = containerBackend
 	}
 }
+
+// WithParallelism sets the maximum number of tasks to run concurrently,
+// a non-positive value enables auto-detection based on the container backend resources.
+func WithParallelism(parallelism int) Option {
+	return func(e *Executor) {
+		e.parallelism = parallelism
+	}
+}
+
+func WithContinueOnFailure() Option {
+	return func(e *Executor) {
+		e.continueOnFailure = true
+	}
+}
+
+// WithRetries sets how many times a task is retried after an infrastructure failure
+// (in addition to the task's own auto_retry property).
+func WithRetries(retries int) Option {
+	return func(e *Executor) {
+		e.retries = retries
+	}
+}
+
+// WithDebugOnFailure enables spawning an interactive shell in the container of the failed task.
+func WithDebugOnFailure() Option {
+	return func(e *Executor) {
+		e.debugOnFailure = true
+	}
+}
+
+// WithDryRun prepares the build without opening the local and remote caches, so that
+// the build can be inspected without changing anything on the disk.
+func WithDryRun() Option {
+	return func(e *Executor) {
+		e.dryRun = true
+	}
+}
+
+// WithArtifactsDir enables storing the artifacts uploaded by the tasks in the specified directory.
+func WithArtifactsDir(dir string) Option {
+	return func(e *Executor) {
+		e.artifacts = artifacts.New(dir)
+	}
+}
+
+// WithTaskLogsDir enables writing the logs of each task and each of its commands to the files
+// in the specified directory.
+func WithTaskLogsDir(dir string) Option {
+	return func(e *Executor) {
+		e.taskLogs = tasklogs.New(dir)
+	}
+}
+
+// WithCacheDir overrides the directory where the local cache is stored, which defaults
+// to the user's cache directory.
+func WithCacheDir(dir string) Option {
+	return func(e *Executor) {
+		e.cacheDir = dir
+	}
+}
+
+// WithCacheNamespaceOptions controls which projects share the local cache.
+func WithCacheNamespaceOptions(namespaceOptions cache.NamespaceOptions) Option {
+	return func(e *Executor) {
+		e.cacheNamespaceOptions = namespaceOptions
+	}
+}
+
+// WithCacheMaxSize enables the eviction of the least recently used cache entries
+// once the project's cache exceeds the specified amount of bytes.
+func WithCacheMaxSize(maxSize uint64) Option {
+	return func(e *Executor) {
+		e.cacheOptions = append(e.cacheOptions, cache.WithMaxSize(maxSize))
+	}
+}
+
+// WithTracer enables recording of the build's timeline into the tracer.
+func WithTracer(tracer *trace.Tracer) Option {
+	return func(e *Executor) {
+		e.tracer = tracer
+	}
+}
+
+// WithRemoteCache enables sharing the cache entries across machines
+// using the specified remote cache in the specified mode.
+func WithRemoteCache(remote cache.Cache, mode string) Option {
+	return func(e *Executor) {
+		e.remoteCache = remote
+		e.remoteCacheMode = mode
+	}
+}
+
+// WithSecrets enables masking of the specified secret values in the task logs.
+//
+// Values of the variables that look sensitive (e.g. GITHUB_TOKEN) are masked regardless.
+func WithSecrets(secrets []string) Option {
+	return func(e *Executor) {
+		e.secrets = append(e.secrets, secrets...)
+	}
+}
+
+// WithSecretEnv enables masking of the values of the specified task environment variables in the task logs.
+func WithSecretEnv(names []string) Option {
+	return func(e *Executor) {
+		e.secretEnv = append(e.secretEnv, names...)
+	}
+}
@@ -0,0 +1,180 @@
+package plan
+
+import (
+	"fmt"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/secrets"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Plan describes what would be executed during the build without actually running anything.
+type Plan struct {
+	Tasks []Task
+}
+
+type Task struct {
+	ID        int64
+	Name      string
+	Labels    []string
+	DependsOn []string
+
+	Instance string
+	Images   []string
+
+	// Resources after clamping them to the ones available for the container backend daemon
+	HasResources bool
+	CPU          float32
+	Memory       uint32
+
+	Timeout     time.Duration
+	Environment map[string]string
+}
+
+// New creates a plan for the build's tasks, ordered in a way that each task
+// comes after its dependencies. When info is nil, resources are not clamped.
+func New(b *build.Build, info *containerbackend.SystemInfo) *Plan {
+	plan := &Plan{}
+
+	for _, task := range dependencyOrder(b) {
+		planTask := Task{
+			ID:          task.ID,
+			Name:        task.Name,
+			Labels:      task.Labels,
+			Instance:    instance.Kind(task.Instance),
+			Images:      instance.Images(task.Instance),
+			Timeout:     task.Timeout,
+			Environment: task.Environment,
+		}
+
+		for _, requiredID := range task.RequiredIDs {
+			if requiredTask := b.GetTask(requiredID); requiredTask != nil {
+				planTask.DependsOn = append(planTask.DependsOn, requiredTask.String())
+			}
+		}
+
+		planTask.CPU, planTask.Memory, planTask.HasResources = instance.Resources(task.Instance)
+		if planTask.HasResources && info != nil {
+			planTask.CPU, planTask.Memory = instance.ClampResources(planTask.CPU, planTask.Memory, info)
+		}
+
+		plan.Tasks = append(plan.Tasks, planTask)
+	}
+
+	return plan
+}
+
+// MaskSecrets hides the secrets in the tasks' environment, since the plan is commonly
+// printed to the same place as the task logs, where the secrets are hidden too.
+func (plan *Plan) MaskSecrets(masker *secrets.Masker) {
+	for i := range plan.Tasks {
+		// The environment is shared with the build's task, so modify a copy
+		maskedEnvironment := make(map[string]string, len(plan.Tasks[i].Environment))
+
+		for key, value := range plan.Tasks[i].Environment {
+			maskedEnvironment[key] = masker.Mask(value)
+		}
+
+		plan.Tasks[i].Environment = maskedEnvironment
+	}
+}
+
+// dependencyOrder sorts the tasks topologically, preferring the tasks with lower IDs
+// when there's a choice, which mimics the order in which the executor picks them.
+func dependencyOrder(b *build.Build) []*build.Task {
+	tasks := b.Tasks()
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	var result []*build.Task
+	visited := make(map[int64]bool)
+
+	for len(result) != len(tasks) {
+		progressed := false
+
+		for _, task := range tasks {
+			if visited[task.ID] {
+				continue
+			}
+
+			ready := true
+			for _, requiredID := range task.RequiredIDs {
+				if b.GetTask(requiredID) != nil && !visited[requiredID] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+
+			visited[task.ID] = true
+			result = append(result, task)
+			progressed = true
+
+			break
+		}
+
+		// Dependency cycle, append the remaining tasks as is
+		if !progressed {
+			for _, task := range tasks {
+				if !visited[task.ID] {
+					result = append(result, task)
+				}
+			}
+			break
+		}
+	}
+
+	return result
+}
+
+// WriteText writes a human-readable representation of the plan.
+func (plan *Plan) WriteText(w io.Writer) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Execution plan (%d task(s)):\n", len(plan.Tasks))
+
+	for i, task := range plan.Tasks {
+		fmt.Fprintf(&sb, "\n%d. %s (%d)\n", i+1, task.Name, task.ID)
+
+		if len(task.Labels) != 0 {
+			fmt.Fprintf(&sb, "   labels: %s\n", strings.Join(task.Labels, ", "))
+		}
+		if len(task.DependsOn) != 0 {
+			fmt.Fprintf(&sb, "   depends on: %s\n", strings.Join(task.DependsOn, ", "))
+		}
+
+		fmt.Fprintf(&sb, "   instance: %s\n", task.Instance)
+		if len(task.Images) != 0 {
+			fmt.Fprintf(&sb, "   image: %s\n", strings.Join(task.Images, ", "))
+		}
+		if task.HasResources {
+			fmt.Fprintf(&sb, "   resources: %g CPU, %d MiB memory\n", task.CPU, task.Memory)
+		}
+		fmt.Fprintf(&sb, "   timeout: %s\n", task.Timeout)
+
+		if len(task.Environment) != 0 {
+			fmt.Fprintf(&sb, "   environment:\n")
+
+			var keys []string
+			for key := range task.Environment {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				fmt.Fprintf(&sb, "     %s=%s\n", key, task.Environment[key])
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
@@ -0,0 +1,108 @@
+package plan_test
+
+import (
+	"bytes"
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance/containerbackend"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/plan"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/secrets"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newBuild(t *testing.T) *build.Build {
+	heavyInstance, err := ptypes.MarshalAny(&api.ContainerInstance{
+		Image:  "golang:latest",
+		Cpu:    8,
+		Memory: 16384,
+	})
+	require.NoError(t, err)
+
+	b, err := build.New(testutil.TempDir(t), []*api.Task{
+		{
+			LocalGroupId:   0,
+			Name:           "deploy",
+			RequiredGroups: []int64{2},
+			Instance:       testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId: 1,
+			Name:         "lint",
+			Instance:     testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId: 2,
+			Name:         "test",
+			Metadata: &api.Task_Metadata{
+				UniqueLabels: []string{"VERSION:1.15"},
+			},
+			Environment: map[string]string{"GOPROXY": "direct"},
+			Instance:    heavyInstance,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	return b
+}
+
+// TestDependencyOrder ensures that the tasks are planned after their dependencies.
+func TestDependencyOrder(t *testing.T) {
+	p := plan.New(newBuild(t), nil)
+
+	var names []string
+	for _, task := range p.Tasks {
+		names = append(names, task.Name)
+	}
+
+	assert.Equal(t, []string{"lint", "test", "deploy"}, names)
+	assert.Equal(t, []string{"test (2)"}, p.Tasks[2].DependsOn)
+}
+
+// TestResourceClamping ensures that the planned resources match the ones the task will actually get.
+func TestResourceClamping(t *testing.T) {
+	p := plan.New(newBuild(t), &containerbackend.SystemInfo{
+		TotalCPUs:        4,
+		TotalMemoryBytes: 8 * 1024 * 1024 * 1024,
+	})
+
+	test := p.Tasks[1]
+	assert.True(t, test.HasResources)
+	assert.EqualValues(t, 4, test.CPU)
+	assert.EqualValues(t, 8192, test.Memory)
+	assert.Equal(t, []string{"golang:latest"}, test.Images)
+}
+
+// TestWriteText ensures that the human-readable plan mentions all the important details.
+func TestWriteText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, plan.New(newBuild(t), nil).WriteText(buf))
+
+	assert.Contains(t, buf.String(), "Execution plan (3 task(s))")
+	assert.Contains(t, buf.String(), "labels: VERSION:1.15")
+	assert.Contains(t, buf.String(), "depends on: test (2)")
+	assert.Contains(t, buf.String(), "instance: container")
+	assert.Contains(t, buf.String(), "image: golang:latest")
+	assert.Contains(t, buf.String(), "resources: 8 CPU, 16384 MiB memory")
+	assert.Contains(t, buf.String(), "timeout: 1h0m0s")
+	assert.Contains(t, buf.String(), "GOPROXY=direct")
+}
+
+// TestMaskSecrets ensures that the plan doesn't reveal the secrets in the tasks' environment.
+func TestMaskSecrets(t *testing.T) {
+	b := newBuild(t)
+
+	p := plan.New(b, nil)
+	p.MaskSecrets(secrets.NewMasker([]string{"direct"}))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, p.WriteText(buf))
+	assert.Contains(t, buf.String(), "GOPROXY="+secrets.MaskedValue)
+	assert.NotContains(t, buf.String(), "GOPROXY=direct")
+
+	// The build's tasks are left intact
+	assert.Equal(t, "direct", b.GetTask(2).Environment["GOPROXY"])
+}
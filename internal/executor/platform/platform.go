@@ -4,6 +4,9 @@ const (
 	// workingVolumeWorkingDir is a working directory relative to the CirrusDir().
 	workingVolumeWorkingDir = "working-dir"
 
+	// dependencyArtifactsDir is a directory with the artifacts of the task's dependencies relative to the CirrusDir().
+	dependencyArtifactsDir = "dependency-artifacts"
+
 	// workingVolumeAgentBinary is the name of the agent binary relative to the CirrusDir().
 	workingVolumeAgentBinary = "cirrus-ci-agent"
 
@@ -29,4 +32,5 @@ type Platform interface {
 
 	CirrusDir() string
 	GenericWorkingDir() string
+	DependencyArtifactsDir() string
 }
@@ -51,3 +51,7 @@ func (platform *UnixPlatform) ContainerCopyCommand(populate bool) *CopyCommand {
 func (platform *UnixPlatform) GenericWorkingDir() string {
 	return path.Join(platform.CirrusDir(), workingVolumeWorkingDir)
 }
+
+func (platform *UnixPlatform) DependencyArtifactsDir() string {
+	return path.Join(platform.CirrusDir(), dependencyArtifactsDir)
+}
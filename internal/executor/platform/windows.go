@@ -68,3 +68,7 @@ func (platform *WindowsPlatform) ContainerCopyCommand(populate bool) *CopyComman
 func (platform *WindowsPlatform) GenericWorkingDir() string {
 	return filepath.Join(platform.CirrusDir(), workingVolumeWorkingDir)
 }
+
+func (platform *WindowsPlatform) DependencyArtifactsDir() string {
+	return filepath.Join(platform.CirrusDir(), dependencyArtifactsDir)
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/commandstatus"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/taskstatus"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       float64         `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report in JUnit XML format, where each task is represented
+// as a test suite and each of its commands is represented as a test case.
+func (report *Report) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{
+		Name: "cirrus",
+	}
+
+	for _, task := range report.Tasks {
+		suite := junitTestSuite{
+			Name: task.Name,
+			Time: task.DurationSeconds,
+			Properties: []junitProperty{
+				{Name: "id", Value: fmt.Sprintf("%d", task.ID)},
+				{Name: "labels", Value: strings.Join(task.Labels, " ")},
+				{Name: "instance", Value: task.Instance},
+				{Name: "status", Value: task.Status},
+				{Name: "allow_failures", Value: fmt.Sprintf("%t", task.AllowFailures)},
+			},
+		}
+
+		if len(task.Labels) != 0 {
+			suite.Name = fmt.Sprintf("%s (%s)", task.Name, strings.Join(task.Labels, " "))
+		}
+
+		for _, command := range task.Commands {
+			testCase := junitTestCase{
+				Name:      command.Name,
+				ClassName: suite.Name,
+				Time:      command.DurationSeconds,
+			}
+
+			switch {
+			case command.Status == commandstatus.Failure.String():
+				testCase.Failure = &junitMessage{Message: fmt.Sprintf("%s failed", command.Name)}
+				suite.Failures++
+			case command.Status == commandstatus.Undefined.String() && task.TimedOut:
+				testCase.Error = &junitMessage{Message: fmt.Sprintf("task %s", taskstatus.TimedOut.String())}
+				suite.Errors++
+			case command.Status == commandstatus.Undefined.String():
+				testCase.Skipped = &junitMessage{Message: fmt.Sprintf("task %s", task.Status)}
+				suite.Skipped++
+			}
+
+			suite.Cases = append(suite.Cases, testCase)
+			suite.Tests++
+		}
+
+		suites.Suites = append(suites.Suites, suite)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Time += suite.Time
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"encoding/json"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/taskstatus"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/instance"
+	"io"
+	"sort"
+)
+
+// Report is a machine-readable summary of the build execution.
+type Report struct {
+	Succeeded bool   `json:"succeeded"`
+	Tasks     []Task `json:"tasks"`
+}
+
+type Task struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Labels          []string  `json:"labels"`
+	Instance        string    `json:"instance"`
+	Status          string    `json:"status"`
+	TimedOut        bool      `json:"timed_out"`
+	AllowFailures   bool      `json:"allow_failures"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Commands        []Command `json:"commands"`
+}
+
+type Command struct {
+	Name               string  `json:"name"`
+	Kind               string  `json:"kind"`
+	Status             string  `json:"status"`
+	ExecutionBehaviour string  `json:"execution_behaviour"`
+	DurationSeconds    float64 `json:"duration_seconds"`
+}
+
+// New captures the current state of the build's tasks and their commands.
+func New(b *build.Build) *Report {
+	report := &Report{
+		Succeeded: true,
+		Tasks:     []Task{},
+	}
+
+	for _, task := range b.Tasks() {
+		status := task.Status()
+
+		if status != taskstatus.Succeeded && !task.AllowFailures {
+			report.Succeeded = false
+		}
+
+		reportTask := Task{
+			ID:              task.ID,
+			Name:            task.Name,
+			Labels:          task.Labels,
+			Instance:        instance.Kind(task.Instance),
+			Status:          status.String(),
+			TimedOut:        status == taskstatus.TimedOut,
+			AllowFailures:   task.AllowFailures,
+			DurationSeconds: task.Duration().Seconds(),
+			Commands:        []Command{},
+		}
+
+		if reportTask.Labels == nil {
+			reportTask.Labels = []string{}
+		}
+
+		for _, command := range task.Commands {
+			reportTask.Commands = append(reportTask.Commands, Command{
+				Name:               command.ProtoCommand.Name,
+				Kind:               command.Kind(),
+				Status:             command.Status().String(),
+				ExecutionBehaviour: command.ProtoCommand.ExecutionBehaviour.String(),
+				DurationSeconds:    command.Duration().Seconds(),
+			})
+		}
+
+		report.Tasks = append(report.Tasks, reportTask)
+	}
+
+	sort.Slice(report.Tasks, func(i, j int) bool {
+		return report.Tasks[i].ID < report.Tasks[j].ID
+	})
+
+	return report
+}
+
+// WriteJSON writes the report in JSON format.
+func (report *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
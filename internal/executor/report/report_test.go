@@ -0,0 +1,99 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/commandstatus"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/taskstatus"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/report"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newBuild(t *testing.T) *build.Build {
+	b, err := build.New(testutil.TempDir(t), []*api.Task{
+		{
+			LocalGroupId: 0,
+			Name:         "lint",
+			Commands: []*api.Command{
+				{
+					Name: "lint",
+					Instruction: &api.Command_ScriptInstruction{
+						ScriptInstruction: &api.ScriptInstruction{Scripts: []string{"true"}},
+					},
+				},
+			},
+			Instance: testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+		{
+			LocalGroupId: 1,
+			Name:         "test",
+			Metadata: &api.Task_Metadata{
+				UniqueLabels: []string{"VERSION:1.15"},
+			},
+			Commands: []*api.Command{
+				{
+					Name: "test",
+					Instruction: &api.Command_ScriptInstruction{
+						ScriptInstruction: &api.ScriptInstruction{Scripts: []string{"false"}},
+					},
+				},
+				{
+					Name: "upload",
+					Instruction: &api.Command_ScriptInstruction{
+						ScriptInstruction: &api.ScriptInstruction{Scripts: []string{"true"}},
+					},
+				},
+			},
+			Instance: testutil.GetBasicContainerInstance(t, "debian:latest"),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.GetTask(0).GetCommand("lint").SetStatus(commandstatus.Success)
+	b.GetTask(1).GetCommand("test").SetStatus(commandstatus.Failure)
+
+	return b
+}
+
+// TestJSON ensures that the task and command statuses are reflected in the JSON report.
+func TestJSON(t *testing.T) {
+	r := report.New(newBuild(t))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.WriteJSON(buf))
+
+	var decoded report.Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+	assert.False(t, decoded.Succeeded)
+	require.Len(t, decoded.Tasks, 2)
+
+	assert.Equal(t, "lint", decoded.Tasks[0].Name)
+	assert.Equal(t, taskstatus.Succeeded.String(), decoded.Tasks[0].Status)
+	assert.Equal(t, "container", decoded.Tasks[0].Instance)
+
+	assert.Equal(t, []string{"VERSION:1.15"}, decoded.Tasks[1].Labels)
+	assert.Equal(t, taskstatus.Failed.String(), decoded.Tasks[1].Status)
+	assert.Equal(t, commandstatus.Failure.String(), decoded.Tasks[1].Commands[0].Status)
+	assert.Equal(t, "script", decoded.Tasks[1].Commands[0].Kind)
+}
+
+// TestJUnit ensures that failed and not executed commands are reported as such in the JUnit report.
+func TestJUnit(t *testing.T) {
+	r := report.New(newBuild(t))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, r.WriteJUnit(buf))
+
+	assert.Contains(t, buf.String(), `<testsuites name="cirrus" tests="3" failures="1" errors="0" skipped="1"`)
+	assert.Contains(t, buf.String(), `<testsuite name="test (VERSION:1.15)"`)
+	assert.Contains(t, buf.String(), `<failure message="test failed"></failure>`)
+	assert.Contains(t, buf.String(), `<skipped message="task failed"></skipped>`)
+}
@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/artifacts"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+)
+
+func (r *RPC) UploadArtifacts(stream api.CirrusCIService_UploadArtifactsServer) error {
+	var upload *artifacts.Upload
+	var authenticated bool
+
+	for {
+		artifactEntry, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			r.logger.Warnf("error while receiving artifacts: %v", err)
+			return err
+		}
+
+		switch x := artifactEntry.Value.(type) {
+		case *api.ArtifactEntry_ArtifactsUpload_:
+			if authenticated {
+				r.logger.Warnf("received multiple artifacts in a single method call")
+				return status.Error(codes.FailedPrecondition, "received multiple artifacts in a single method call")
+			}
+			task, err := r.build.GetTaskFromIdentification(x.ArtifactsUpload.TaskIdentification, r.clientSecret)
+			if err != nil {
+				return err
+			}
+			authenticated = true
+
+			// Simply discard the artifacts if there's no place to store them
+			if r.artifacts == nil {
+				continue
+			}
+
+			span := r.tracer.StartInLane("artifacts upload", trace.TaskLane(task.ID))
+			span.SetAttribute("name", x.ArtifactsUpload.Name)
+			defer span.End()
+
+			upload, err = r.artifacts.Upload(task.UniqueName(), x.ArtifactsUpload.Name, x.ArtifactsUpload.Type,
+				x.ArtifactsUpload.Format)
+			if err != nil {
+				r.logger.Debugf("error while initializing artifacts upload: %v", err)
+				return status.Error(codes.Internal, "failed to initialize artifacts upload")
+			}
+			r.logger.Debugf("receiving artifacts %s", x.ArtifactsUpload.Name)
+		case *api.ArtifactEntry_Chunk:
+			if !authenticated {
+				return status.Error(codes.PermissionDenied, "not authenticated")
+			}
+			if upload == nil {
+				continue
+			}
+			if err := upload.Write(x.Chunk.ArtifactPath, x.Chunk.Data); err != nil {
+				r.logger.Debugf("error while processing artifact chunk: %v", err)
+				return status.Error(codes.Internal, "failed to process artifact chunk")
+			}
+		}
+	}
+
+	if upload != nil {
+		if err := upload.Finalize(); err != nil {
+			r.logger.Debugf("error while finalizing artifacts upload: %v", err)
+			return status.Error(codes.Internal, "failed to finalize artifacts upload")
+		}
+	}
+
+	if err := stream.SendAndClose(&api.UploadArtifactsResponse{}); err != nil {
+		r.logger.Warnf("error while closing artifacts stream: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/cirruslabs/cirrus-ci-agent/api"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/cache"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"io"
@@ -12,9 +13,16 @@ import (
 const sendBufSize = 1024 * 1024
 
 func (r *RPC) UploadCache(stream api.CirrusCIService_UploadCacheServer) error {
-	var putOp *cache.PutOperation
+	var putOp cache.PutOperation
 	var bytesSaved int64
 
+	// Don't leave the partially received blob behind on errors
+	defer func() {
+		if putOp != nil {
+			putOp.Abort()
+		}
+	}()
+
 	for {
 		cacheEntry, err := stream.Recv()
 		if err == io.EOF {
@@ -31,10 +39,13 @@ func (r *RPC) UploadCache(stream api.CirrusCIService_UploadCacheServer) error {
 				r.logger.Warnf("received multiple cache entries in a single method call")
 				return status.Error(codes.FailedPrecondition, "received multiple cache entries in a single method call")
 			}
-			_, err := r.build.GetTaskFromIdentification(x.Key.TaskIdentification, r.clientSecret)
+			task, err := r.build.GetTaskFromIdentification(x.Key.TaskIdentification, r.clientSecret)
 			if err != nil {
 				return err
 			}
+			span := r.tracer.StartInLane("cache upload", trace.TaskLane(task.ID))
+			span.SetAttribute("key", x.Key.CacheKey)
+			defer span.End()
 			putOp, err = r.build.Cache.Put(x.Key.CacheKey)
 			if err != nil {
 				r.logger.Debugf("error while initializing cache put operation: %v", err)
@@ -76,11 +87,15 @@ func (r *RPC) UploadCache(stream api.CirrusCIService_UploadCacheServer) error {
 }
 
 func (r *RPC) DownloadCache(req *api.DownloadCacheRequest, stream api.CirrusCIService_DownloadCacheServer) error {
-	_, err := r.build.GetTaskFromIdentification(req.TaskIdentification, r.clientSecret)
+	task, err := r.build.GetTaskFromIdentification(req.TaskIdentification, r.clientSecret)
 	if err != nil {
 		return err
 	}
 
+	span := r.tracer.StartInLane("cache download", trace.TaskLane(task.ID))
+	span.SetAttribute("key", req.CacheKey)
+	defer span.End()
+
 	file, err := r.build.Cache.Get(req.CacheKey)
 	if err != nil {
 		r.logger.Debugf("error while getting cache blob with key %s: %v", req.CacheKey, err)
@@ -125,22 +140,23 @@ func (r *RPC) CacheInfo(ctx context.Context, req *api.CacheInfoRequest) (*api.Ca
 
 	r.logger.Debugf("sending info about cache key %s", req.CacheKey)
 
-	file, err := r.build.Cache.Get(req.CacheKey)
+	entry, err := r.build.Cache.Info(req.CacheKey)
 	if err != nil {
+		r.logger.Debugf("error while getting info about cache blob with key %s: %v", req.CacheKey, err)
 		return nil, status.Errorf(codes.NotFound, "cache blob with the specified key not found")
 	}
-	defer file.Close()
 
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, err
+	// Remote cache might not know when the blob was created
+	var creationTimestamp int64
+	if !entry.CreatedAt.IsZero() {
+		creationTimestamp = entry.CreatedAt.Unix()
 	}
 
 	response := api.CacheInfoResponse{
 		Info: &api.CacheInfo{
 			Key:               req.CacheKey,
-			SizeInBytes:       fileInfo.Size(),
-			CreationTimestamp: fileInfo.ModTime().Unix(),
+			SizeInBytes:       entry.Size,
+			CreationTimestamp: creationTimestamp,
 		},
 	}
 
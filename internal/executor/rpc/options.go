@@ -1,6 +1,10 @@
 package rpc
 
 import (
+	"github.com/cirruslabs/cirrus-cli/internal/executor/artifacts"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/secrets"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/tasklogs"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/cirruslabs/echelon"
 )
 
@@ -11,3 +15,29 @@ func WithLogger(logger *echelon.Logger) Option {
 		r.logger = logger
 	}
 }
+
+func WithTracer(tracer *trace.Tracer) Option {
+	return func(r *RPC) {
+		r.tracer = tracer
+	}
+}
+
+func WithArtifacts(store *artifacts.Store) Option {
+	return func(r *RPC) {
+		r.artifacts = store
+	}
+}
+
+func WithSecrets(values []string) Option {
+	return func(r *RPC) {
+		if len(values) != 0 {
+			r.masker = secrets.NewMasker(values)
+		}
+	}
+}
+
+func WithTaskLogs(store *tasklogs.Store) Option {
+	return func(r *RPC) {
+		r.taskLogs = store
+	}
+}
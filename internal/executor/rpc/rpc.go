@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/artifacts"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/build/commandstatus"
 	"github.com/cirruslabs/cirrus-cli/internal/executor/heuristic"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/secrets"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/tasklogs"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
 	"github.com/cirruslabs/echelon"
 	"github.com/cirruslabs/echelon/renderers"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -23,6 +27,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	// Registers a gzip compressor needed for streaming logs from the agent.
 	_ "google.golang.org/grpc/encoding/gzip"
@@ -41,9 +46,13 @@ type RPC struct {
 	serverWaitGroup            sync.WaitGroup
 	serverSecret, clientSecret string
 
-	build *build.Build
+	build     *build.Build
+	artifacts *artifacts.Store
+	masker    *secrets.Masker
+	taskLogs  *tasklogs.Store
 
 	logger *echelon.Logger
+	tracer *trace.Tracer
 }
 
 func New(build *build.Build, opts ...Option) *RPC {
@@ -177,6 +186,10 @@ func (r *RPC) InitialCommands(
 		return nil, err
 	}
 
+	// Mark the moment the agent has started and connected to us
+	now := time.Now()
+	r.tracer.Record("agent connected", trace.TaskLane(task.ID), now, now, nil)
+
 	return &api.CommandsResponse{
 		Environment:       task.Environment,
 		Commands:          task.ProtoCommands(),
@@ -219,25 +232,46 @@ func (r *RPC) ReportSingleCommand(
 
 func (r *RPC) getCommandLogger(task *build.Task, command *build.Command) *echelon.Logger {
 	commandLoggerScope := fmt.Sprintf("'%s'", command.ProtoCommand.Name)
-	command.ProtoCommand.GetScriptInstruction()
-	switch command.ProtoCommand.Instruction.(type) {
-	case *api.Command_ScriptInstruction:
-		commandLoggerScope += " script"
-	case *api.Command_BackgroundScriptInstruction:
-		commandLoggerScope += " background script"
-	case *api.Command_CacheInstruction:
-		commandLoggerScope += " cache"
-	case *api.Command_ArtifactsInstruction:
-		commandLoggerScope += " artifacts"
+	if kind := command.Kind(); kind != "" {
+		commandLoggerScope += " " + kind
 	}
 	return r.logger.Scoped(task.UniqueDescription()).Scoped(commandLoggerScope)
 }
 
 func (r *RPC) StreamLogs(stream api.CirrusCIService_StreamLogsServer) error {
 	var currentTaskName string
+	var currentTaskDescription string
 	var currentCommand string
 	streamLogger := r.logger
 
+	writeLog := func(log string) {
+		logChunk(streamLogger, log)
+
+		if r.taskLogs == nil {
+			return
+		}
+
+		if err := r.taskLogs.AppendCommandLog(currentTaskDescription, currentCommand, []byte(log)); err != nil {
+			streamLogger.Warnf("Error while writing logs to a file: %v", err)
+		}
+	}
+
+	// Secrets might be split between the chunks, so the masking is stateful
+	var maskingStream *secrets.Stream
+	if r.masker != nil {
+		maskingStream = r.masker.Stream()
+	}
+
+	flush := func() {
+		if maskingStream == nil {
+			return
+		}
+
+		if rest := maskingStream.Flush(); rest != "" {
+			writeLog(rest)
+		}
+	}
+
 	for {
 		logEntry, err := stream.Recv()
 		if err == io.EOF {
@@ -254,7 +288,12 @@ func (r *RPC) StreamLogs(stream api.CirrusCIService_StreamLogsServer) error {
 			if err != nil {
 				return err
 			}
+
+			// Don't carry over the text held back from the previous command's logs
+			flush()
+
 			currentTaskName = task.Name
+			currentTaskDescription = task.UniqueDescription()
 			currentCommand = x.Key.CommandName
 
 			command := task.GetCommand(currentCommand)
@@ -263,6 +302,8 @@ func (r *RPC) StreamLogs(stream api.CirrusCIService_StreamLogsServer) error {
 					currentCommand)
 			}
 
+			command.MarkStarted()
+
 			streamLogger = r.getCommandLogger(task, command)
 			streamLogger.Debugf("begin streaming logs")
 		case *api.LogEntry_Chunk:
@@ -272,18 +313,21 @@ func (r *RPC) StreamLogs(stream api.CirrusCIService_StreamLogsServer) error {
 
 			streamLogger.Debugf("received log chunk of %d bytes", len(x.Chunk.Data))
 
-			// Ignore the newline at the end of the chunk,
-			// echelon's Infof() below already adds one
-			log := strings.TrimSuffix(string(x.Chunk.Data), "\n")
+			log := string(x.Chunk.Data)
 
-			logLines := strings.Split(log, "\n")
-
-			for _, logLine := range logLines {
-				streamLogger.Infof(logLine)
+			if maskingStream != nil {
+				log = maskingStream.Mask(log)
+				if log == "" {
+					continue
+				}
 			}
+
+			writeLog(log)
 		}
 	}
 
+	flush()
+
 	if err := stream.SendAndClose(&api.UploadLogsResponse{}); err != nil {
 		streamLogger.Warnf("Error while closing log stream: %v", err)
 		return err
@@ -292,6 +336,39 @@ func (r *RPC) StreamLogs(stream api.CirrusCIService_StreamLogsServer) error {
 	return nil
 }
 
+func logChunk(logger *echelon.Logger, log string) {
+	// Ignore the newline at the end of the chunk,
+	// echelon's Infof() below already adds one
+	log = strings.TrimSuffix(log, "\n")
+
+	logLines := strings.Split(log, "\n")
+
+	for _, logLine := range logLines {
+		logger.Infof(logLine)
+	}
+}
+
+// mask hides the secrets in a message that doesn't come from the command logs
+// (which are masked as they're streamed), e.g. in the agent's error message.
+func (r *RPC) mask(message string) string {
+	if r.masker == nil {
+		return message
+	}
+
+	return r.masker.Mask(message)
+}
+
+// appendTaskLog records the message that doesn't belong to any of the commands in the task's log file.
+func (r *RPC) appendTaskLog(task *build.Task, message string) {
+	if r.taskLogs == nil {
+		return
+	}
+
+	if err := r.taskLogs.AppendTaskLog(task.UniqueDescription(), message); err != nil {
+		r.logger.Scoped(task.UniqueDescription()).Warnf("Error while writing logs to a file: %v", err)
+	}
+}
+
 func (r *RPC) Heartbeat(ctx context.Context, req *api.HeartbeatRequest) (*api.HeartbeatResponse, error) {
 	task, err := r.build.GetTaskFromIdentification(req.TaskIdentification, r.clientSecret)
 	if err != nil {
@@ -309,7 +386,9 @@ func (r *RPC) ReportAgentError(ctx context.Context, req *api.ReportAgentProblemR
 		return nil, err
 	}
 
-	r.logger.Scoped(task.UniqueDescription()).Debugf("agent error: %s", req.Message)
+	message := r.mask(req.Message)
+	r.logger.Scoped(task.UniqueDescription()).Debugf("agent error: %s", message)
+	r.appendTaskLog(task, "agent error: "+message)
 
 	return &empty.Empty{}, nil
 }
@@ -320,7 +399,9 @@ func (r *RPC) ReportAgentWarning(ctx context.Context, req *api.ReportAgentProble
 		return nil, err
 	}
 
-	r.logger.Scoped(task.UniqueDescription()).Debugf("agent warning: %s", req.Message)
+	message := r.mask(req.Message)
+	r.logger.Scoped(task.UniqueDescription()).Debugf("agent warning: %s", message)
+	r.appendTaskLog(task, "agent warning: "+message)
 
 	return &empty.Empty{}, nil
 }
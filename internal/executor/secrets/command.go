@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	// CommandNameEnvVariable holds the name of the variable that is being decrypted by the command.
+	CommandNameEnvVariable = "CIRRUS_SECRET_NAME"
+
+	// CommandCiphertextEnvVariable holds the ciphertext of the variable that is being decrypted by the command.
+	CommandCiphertextEnvVariable = "CIRRUS_SECRET_CIPHERTEXT"
+
+	// commandNotFoundExitCode is returned by the command when it doesn't know the secret.
+	commandNotFoundExitCode = 2
+)
+
+// Command provides the secrets by running a shell command (e.g. "pass show ci/$CIRRUS_SECRET_NAME")
+// that prints the plaintext value to its standard output or exits with code 2 if it doesn't know the secret.
+type Command struct {
+	command string
+}
+
+func NewCommand(command string) *Command {
+	return &Command{
+		command: command,
+	}
+}
+
+func (command *Command) Decrypt(name string, ciphertext string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command.command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", command.command)
+	}
+
+	cmd.Env = append(os.Environ(),
+		CommandNameEnvVariable+"="+name,
+		CommandCiphertextEnvVariable+"="+ciphertext,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == commandNotFoundExitCode {
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("%w: %q failed: %v: %s", ErrProviderFailed, command.command, err,
+			strings.TrimSpace(stderr.String()))
+	}
+
+	// Most of the tools (e.g. pass) terminate the secret with a newline
+	return strings.TrimSuffix(strings.TrimSuffix(stdout.String(), "\n"), "\r"), nil
+}
@@ -0,0 +1,25 @@
+package secrets
+
+import "os"
+
+// Environment provides the secrets from the environment variables of the CLI
+// process that are named after the task's variables with a prefix (e.g. TOKEN
+// is resolved from the CIRRUS_SECRET_TOKEN when the prefix is "CIRRUS_SECRET_").
+type Environment struct {
+	prefix string
+}
+
+func NewEnvironment(prefix string) *Environment {
+	return &Environment{
+		prefix: prefix,
+	}
+}
+
+func (environment *Environment) Decrypt(name string, ciphertext string) (string, error) {
+	plaintext, ok := os.LookupEnv(environment.prefix + name)
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return plaintext, nil
+}
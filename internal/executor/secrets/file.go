@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+)
+
+// File provides the secrets from a YAML or JSON file that maps either the ciphertexts
+// (the part between the brackets of ENCRYPTED[...]) or the variable names to the plaintext values.
+type File struct {
+	secrets map[string]string
+}
+
+func NewFile(path string) (*File, error) {
+	fileBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderFailed, err)
+	}
+
+	file := &File{}
+
+	if err := yaml.Unmarshal(fileBytes, &file.secrets); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse %s: %v", ErrProviderFailed, path, err)
+	}
+
+	return file, nil
+}
+
+func (file *File) Decrypt(name string, ciphertext string) (string, error) {
+	// Ciphertexts take precedence since they're more specific
+	for _, key := range []string{ciphertext, name} {
+		if plaintext, ok := file.secrets[key]; ok {
+			return plaintext, nil
+		}
+	}
+
+	return "", ErrNotFound
+}
@@ -0,0 +1,117 @@
+package secrets
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaskedValue replaces the secrets in the logs, just like in Cirrus CI.
+const MaskedValue = "HIDDEN-BY-CIRRUS-CI"
+
+// Masker hides the secret values in the task logs.
+type Masker struct {
+	secrets []string
+}
+
+func NewMasker(secrets []string) *Masker {
+	seen := make(map[string]struct{})
+
+	var unique []string
+
+	for _, secret := range secrets {
+		if _, ok := seen[secret]; ok || secret == "" {
+			continue
+		}
+
+		seen[secret] = struct{}{}
+		unique = append(unique, secret)
+	}
+
+	// Longer secrets go first, so that a secret that contains another
+	// secret is masked completely
+	sort.SliceStable(unique, func(i, j int) bool {
+		return len(unique[i]) > len(unique[j])
+	})
+
+	return &Masker{
+		secrets: unique,
+	}
+}
+
+// Mask hides the secrets in a complete piece of text.
+func (masker *Masker) Mask(s string) string {
+	masked, _ := masker.mask(s, true)
+
+	return masked
+}
+
+// Stream returns a masker for the text that arrives in chunks, which
+// might split the secrets in between them.
+func (masker *Masker) Stream() *Stream {
+	return &Stream{
+		masker: masker,
+	}
+}
+
+// mask replaces the secrets in s. Unless the s is final, it stops at the first position
+// where the rest of the s might turn out to be a secret once more text arrives and
+// returns that rest separately.
+func (masker *Masker) mask(s string, final bool) (string, string) {
+	var result strings.Builder
+
+	for i := 0; i < len(s); {
+		secret, complete := masker.match(s[i:], final)
+
+		switch {
+		case secret != "" && complete:
+			result.WriteString(MaskedValue)
+			i += len(secret)
+		case secret != "":
+			return result.String(), s[i:]
+		default:
+			result.WriteByte(s[i])
+			i++
+		}
+	}
+
+	return result.String(), ""
+}
+
+// match returns the longest secret that either starts the s (complete) or, unless
+// the s is final, that the s is a beginning of (incomplete).
+func (masker *Masker) match(s string, final bool) (string, bool) {
+	for _, secret := range masker.secrets {
+		if strings.HasPrefix(s, secret) {
+			return secret, true
+		}
+
+		if !final && strings.HasPrefix(secret, s) {
+			return secret, false
+		}
+	}
+
+	return "", false
+}
+
+// Stream masks the secrets in the text that arrives in chunks.
+type Stream struct {
+	masker  *Masker
+	pending string
+}
+
+// Mask returns the masked chunk, holding back its ending if it might be the beginning of a secret.
+func (stream *Stream) Mask(chunk string) string {
+	var masked string
+
+	masked, stream.pending = stream.masker.mask(stream.pending+chunk, false)
+
+	return masked
+}
+
+// Flush returns the text held back by Mask() when no more chunks are expected.
+func (stream *Stream) Flush() string {
+	masked, _ := stream.masker.mask(stream.pending, true)
+	stream.pending = ""
+
+	return masked
+}
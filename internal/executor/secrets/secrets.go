@@ -0,0 +1,83 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	placeholderPrefix = "ENCRYPTED["
+	placeholderSuffix = "]"
+)
+
+var (
+	ErrNotFound       = errors.New("secret not found")
+	ErrProviderFailed = errors.New("secrets provider failed")
+)
+
+// Provider decrypts the ENCRYPTED[...] variables locally, since the actual
+// decryption keys are only available to the Cirrus CI.
+type Provider interface {
+	// Decrypt returns the plaintext value of the ciphertext assigned to the environment
+	// variable with the specified name or ErrNotFound if the provider doesn't know it.
+	Decrypt(name string, ciphertext string) (string, error)
+}
+
+// Ciphertext extracts the ciphertext from the ENCRYPTED[...] placeholder.
+func Ciphertext(value string) (string, bool) {
+	if !strings.HasPrefix(value, placeholderPrefix) || !strings.HasSuffix(value, placeholderSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(value, placeholderPrefix), placeholderSuffix), true
+}
+
+// Resolve replaces the ENCRYPTED[...] placeholders in the environment with their plaintext values
+// and returns these values, so that they can be masked later. Placeholders that are unknown
+// to the provider are left as is.
+func Resolve(provider Provider, env map[string]string) ([]string, error) {
+	var resolved []string
+
+	for name, value := range env {
+		ciphertext, ok := Ciphertext(value)
+		if !ok {
+			continue
+		}
+
+		plaintext, err := provider.Decrypt(name, ciphertext)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+
+		env[name] = plaintext
+		resolved = append(resolved, plaintext)
+	}
+
+	return resolved, nil
+}
+
+type chain []Provider
+
+// Chain returns a provider that consults the specified providers in order
+// until one of them knows the secret.
+func Chain(providers ...Provider) Provider {
+	return chain(providers)
+}
+
+func (providers chain) Decrypt(name string, ciphertext string) (string, error) {
+	for _, provider := range providers {
+		plaintext, err := provider.Decrypt(name, ciphertext)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		return plaintext, err
+	}
+
+	return "", ErrNotFound
+}
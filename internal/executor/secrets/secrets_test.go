@@ -0,0 +1,111 @@
+package secrets_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/secrets"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestResolve ensures that the known ENCRYPTED[...] variables are decrypted
+// by the first provider that knows them and the unknown ones are left as is.
+func TestResolve(t *testing.T) {
+	secretsPath := filepath.Join(testutil.TempDir(t), "secrets.yml")
+	require.NoError(t, ioutil.WriteFile(secretsPath, []byte("abc123: by ciphertext\nBY_NAME: by name\n"), 0600))
+
+	file, err := secrets.NewFile(secretsPath)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Setenv("TEST_SECRET_FROM_ENV", "from environment"))
+	defer os.Unsetenv("TEST_SECRET_FROM_ENV")
+
+	env := map[string]string{
+		"BY_CIPHERTEXT": "ENCRYPTED[abc123]",
+		"BY_NAME":       "ENCRYPTED[def456]",
+		"FROM_ENV":      "ENCRYPTED[ghi789]",
+		"UNKNOWN":       "ENCRYPTED[jkl012]",
+		"PLAIN":         "plain value",
+	}
+
+	resolved, err := secrets.Resolve(secrets.Chain(file, secrets.NewEnvironment("TEST_SECRET_")), env)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"by ciphertext", "by name", "from environment"}, resolved)
+	assert.Equal(t, map[string]string{
+		"BY_CIPHERTEXT": "by ciphertext",
+		"BY_NAME":       "by name",
+		"FROM_ENV":      "from environment",
+		"UNKNOWN":       "ENCRYPTED[jkl012]",
+		"PLAIN":         "plain value",
+	}, env)
+}
+
+// TestCommand ensures that the secrets can be retrieved from an external command.
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command relies on a POSIX shell")
+	}
+
+	command := secrets.NewCommand(`case "$CIRRUS_SECRET_NAME" in
+		TOKEN) echo "token for $CIRRUS_SECRET_CIPHERTEXT";;
+		BROKEN) echo "something went wrong" >&2; exit 1;;
+		*) exit 2;;
+	esac`)
+
+	plaintext, err := command.Decrypt("TOKEN", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "token for abc123", plaintext)
+
+	_, err = command.Decrypt("UNKNOWN", "abc123")
+	assert.True(t, errors.Is(err, secrets.ErrNotFound))
+
+	_, err = command.Decrypt("BROKEN", "abc123")
+	assert.True(t, errors.Is(err, secrets.ErrProviderFailed))
+	assert.Contains(t, err.Error(), "something went wrong")
+}
+
+// TestMasker ensures that the secrets are hidden completely, even when one contains another.
+func TestMasker(t *testing.T) {
+	masker := secrets.NewMasker([]string{"secret", "", "top-secret"})
+
+	assert.Equal(t, "the HIDDEN-BY-CIRRUS-CI and HIDDEN-BY-CIRRUS-CI values",
+		masker.Mask("the secret and top-secret values"))
+}
+
+// TestMaskerStream ensures that the secrets are hidden even when they're split between the chunks.
+func TestMaskerStream(t *testing.T) {
+	stream := secrets.NewMasker([]string{"secret", "secret-and-more"}).Stream()
+
+	var result string
+	for _, chunk := range []string{"a se", "cr", "et here\n", "and a secret-and-mo", "re there, but not a sec"} {
+		result += stream.Mask(chunk)
+	}
+
+	// The possible beginning of a secret is held back until the end of the stream
+	assert.Equal(t, "a HIDDEN-BY-CIRRUS-CI here\nand a HIDDEN-BY-CIRRUS-CI there, but not a ", result)
+	assert.Equal(t, "sec", stream.Flush())
+}
+
+// TestSensitive ensures that the values of the explicitly specified
+// and the sensitive-looking variables are considered secrets, unless they're too short.
+func TestSensitive(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_TOKEN":   "ghp_abcdef",
+		"DB_PASSWORD":    "hunter2",
+		"API_KEY":        "key-value",
+		"USE_TOKEN":      "1",
+		"NPM_TOKEN":      "ENCRYPTED[abc123]",
+		"PLAIN":          "plain value",
+		"EMPTY_SECRET":   "",
+		"SHORT_EXPLICIT": "ab",
+	}
+
+	assert.ElementsMatch(t, []string{"ghp_abcdef", "hunter2", "key-value"},
+		secrets.Sensitive(env, []string{"API_KEY", "SHORT_EXPLICIT", "EMPTY_SECRET"}))
+}
@@ -0,0 +1,47 @@
+package secrets
+
+import "strings"
+
+// sensitiveSuffixes are the variable name endings that suggest that the variable holds a secret.
+var sensitiveSuffixes = []string{"_TOKEN", "_PASSWORD"}
+
+// minSensitiveValueLength prevents masking the short values (e.g. "1" or "true"), which are unlikely
+// to be secrets and would otherwise make the logs unreadable by masking every occurrence of them.
+const minSensitiveValueLength = 4
+
+// Sensitive returns the values of the explicitly specified variables and the
+// variables whose names suggest that they hold secrets (e.g. GITHUB_TOKEN),
+// except for the values that are too short to be masked.
+func Sensitive(env map[string]string, names []string) []string {
+	var result []string
+
+	explicit := make(map[string]struct{})
+	for _, name := range names {
+		explicit[name] = struct{}{}
+	}
+
+	for name, value := range env {
+		// Placeholders that weren't decrypted are safe to show
+		if _, ok := Ciphertext(value); ok || len(value) < minSensitiveValueLength {
+			continue
+		}
+
+		if _, ok := explicit[name]; ok || looksSensitive(name) {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+func looksSensitive(name string) bool {
+	name = "_" + strings.ToUpper(name)
+
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
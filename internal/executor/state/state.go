@@ -0,0 +1,202 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/taskstatus"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+var (
+	ErrNotFound       = errors.New("no previous build found")
+	ErrConfigChanged  = errors.New("configuration has changed since the previous build")
+	ErrFailedToLoad   = errors.New("failed to load the previous build state")
+	ErrFailedToSave   = errors.New("failed to save the build state")
+	ErrFailedToLocate = errors.New("failed to locate the build state")
+)
+
+// State describes the outcome of the previous build, which allows
+// re-running only the tasks that didn't succeed.
+type State struct {
+	ConfigHash string `json:"config_hash"`
+	Tasks      []Task `json:"tasks"`
+}
+
+type Task struct {
+	ID     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+	Status string   `json:"status"`
+}
+
+// ConfigHash returns a digest of the configuration suitable for detecting its changes between builds.
+func ConfigHash(config string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(config)))
+}
+
+func New(configHash string) *State {
+	return &State{
+		ConfigHash: configHash,
+	}
+}
+
+// Load retrieves the state of the previous build in the specified project directory.
+func Load(dir string, projectDir string) (*State, error) {
+	path, err := statePath(dir, projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	stateBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrFailedToLoad, err)
+	}
+
+	var state State
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedToLoad, err)
+	}
+
+	return &state, nil
+}
+
+// Save persists the state for the follow-up builds in the specified project directory.
+func (state *State) Save(dir string, projectDir string) error {
+	path, err := statePath(dir, projectDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("%w: %v", ErrFailedToSave, err)
+	}
+
+	stateBytes, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFailedToSave, err)
+	}
+
+	// Write the state atomically since multiple CLI instances might be running for the same project
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), ".temporary-state-")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFailedToSave, err)
+	}
+
+	if _, err := tmpFile.Write(stateBytes); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+
+		return fmt.Errorf("%w: %v", ErrFailedToSave, err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpFile.Name())
+
+		return fmt.Errorf("%w: %v", ErrFailedToSave, err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("%w: %v", ErrFailedToSave, err)
+	}
+
+	return nil
+}
+
+// Update records the statuses of the tasks from the build, preserving the statuses
+// of the tasks that were not part of it.
+func (state *State) Update(b *build.Build) {
+	tasks := make(map[int64]Task)
+
+	for _, task := range state.Tasks {
+		tasks[task.ID] = task
+	}
+
+	for _, task := range b.Tasks() {
+		tasks[task.ID] = Task{
+			ID:     task.ID,
+			Name:   task.Name,
+			Labels: task.Labels,
+			Status: task.Status().String(),
+		}
+	}
+
+	state.Tasks = state.Tasks[:0]
+	for _, task := range tasks {
+		state.Tasks = append(state.Tasks, task)
+	}
+
+	sort.Slice(state.Tasks, func(i, j int) bool {
+		return state.Tasks[i].ID < state.Tasks[j].ID
+	})
+}
+
+// CheckConfigHash ensures that the state was produced by the build with the same configuration.
+func (state *State) CheckConfigHash(configHash string) error {
+	if state.ConfigHash != configHash {
+		return ErrConfigChanged
+	}
+
+	return nil
+}
+
+// FailedTaskIDs returns IDs of the tasks that have failed or timed out, along with the tasks
+// that were aborted, skipped or haven't started at all because of these failures.
+func (state *State) FailedTaskIDs() (result []int64) {
+	for _, task := range state.Tasks {
+		switch task.Status {
+		case taskstatus.Failed.String(), taskstatus.TimedOut.String(),
+			taskstatus.Aborted.String(), taskstatus.Skipped.String(), taskstatus.New.String():
+			result = append(result, task.ID)
+		}
+	}
+
+	return
+}
+
+// UnfinishedTaskIDs returns IDs of the specified tasks that haven't succeeded in the previous builds.
+func (state *State) UnfinishedTaskIDs(allIDs []int64) (result []int64) {
+	succeeded := make(map[int64]struct{})
+
+	for _, task := range state.Tasks {
+		if task.Status == taskstatus.Succeeded.String() {
+			succeeded[task.ID] = struct{}{}
+		}
+	}
+
+	for _, id := range allIDs {
+		if _, ok := succeeded[id]; !ok {
+			result = append(result, id)
+		}
+	}
+
+	return
+}
+
+func statePath(dir string, projectDir string) (string, error) {
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrFailedToLocate, err)
+		}
+		dir = userCacheDir
+	}
+
+	absoluteProjectDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFailedToLocate, err)
+	}
+
+	projectHash := sha256.Sum256([]byte(absoluteProjectDir))
+
+	return filepath.Join(dir, "cirrus", "builds", fmt.Sprintf("%x.json", projectHash)), nil
+}
@@ -0,0 +1,55 @@
+package state_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/build/taskstatus"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/state"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// TestSaveAndLoad ensures that the state survives the round-trip and that
+// the tasks that didn't succeed are selected for re-running.
+func TestSaveAndLoad(t *testing.T) {
+	cacheDir := testutil.TempDir(t)
+	projectDir := testutil.TempDir(t)
+
+	_, err := state.Load(cacheDir, projectDir)
+	require.True(t, errors.Is(err, state.ErrNotFound))
+
+	var protoTasks []*api.Task
+	for id := int64(0); id < 5; id++ {
+		protoTasks = append(protoTasks, &api.Task{
+			LocalGroupId: id,
+			Instance:     testutil.GetBasicContainerInstance(t, "debian:latest"),
+		})
+	}
+
+	b, err := build.New(projectDir, protoTasks, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.GetTask(0).SetStatus(taskstatus.Succeeded)
+	b.GetTask(1).SetStatus(taskstatus.Failed)
+	b.GetTask(2).SetStatus(taskstatus.Skipped)
+	b.GetTask(3).SetStatus(taskstatus.Aborted)
+
+	configHash := state.ConfigHash("task:\n  script: true\n")
+
+	buildState := state.New(configHash)
+	buildState.Update(b)
+	require.NoError(t, buildState.Save(cacheDir, projectDir))
+
+	loadedState, err := state.Load(cacheDir, projectDir)
+	require.NoError(t, err)
+
+	require.NoError(t, loadedState.CheckConfigHash(configHash))
+	require.True(t, errors.Is(loadedState.CheckConfigHash(state.ConfigHash("")), state.ErrConfigChanged))
+
+	assert.Equal(t, []int64{1, 2, 3, 4}, loadedState.FailedTaskIDs())
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, loadedState.UnfinishedTaskIDs([]int64{0, 1, 2, 3, 4, 5}))
+}
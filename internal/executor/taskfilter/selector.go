@@ -0,0 +1,245 @@
+package taskfilter
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidSelector = errors.New("invalid task selector")
+
+	errTrailingBackslash = errors.New("trailing backslash")
+	errUnterminatedClass = errors.New("unterminated character class")
+)
+
+// Matcher decides whether the task satisfies a certain criteria.
+type Matcher func(task *api.Task) bool
+
+// Selector describes which tasks should be kept when filtering.
+type Selector struct {
+	// Task is kept if it's matched by any of these (or if there are none)...
+	Include []Matcher
+
+	// ...and it's matched by all of these...
+	Require []Matcher
+
+	// ...and it's not matched by any of these.
+	Exclude []Matcher
+
+	// Whether to also keep the transitive dependencies of the selected tasks
+	// instead of considering them satisfied.
+	WithDependencies bool
+}
+
+// NameMatcher matches tasks either by a case-insensitive glob pattern (e.g. "lint*") applied
+// to the task name, or, if no pattern characters are present, by the exact task name optionally
+// followed by the task labels (e.g. "test VERSION:1.15").
+//
+// Unlike the file path globs, "*" and "?" also match the "/" character, since it's
+// not special in the task names (e.g. "docker*" matches "docker/build").
+func NameMatcher(selector string) (Matcher, error) {
+	if !strings.ContainsAny(selector, "*?[") {
+		return func(task *api.Task) bool {
+			return matchesExactTask(task, selector)
+		}, nil
+	}
+
+	re, err := compileGlob(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad glob pattern %q: %v", ErrInvalidSelector, selector, err)
+	}
+
+	return func(task *api.Task) bool {
+		return re.MatchString(task.Name)
+	}, nil
+}
+
+// RegexMatcher matches tasks whose name contains a match of the regular expression.
+func RegexMatcher(expr string) (Matcher, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad regular expression %q: %v", ErrInvalidSelector, expr, err)
+	}
+
+	return func(task *api.Task) bool {
+		return re.MatchString(task.Name)
+	}, nil
+}
+
+// LabelMatcher matches tasks that have a label (e.g. "VERSION:1.15") matching
+// the case-insensitive glob pattern.
+func LabelMatcher(label string) (Matcher, error) {
+	re, err := compileGlob(label)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad label pattern %q: %v", ErrInvalidSelector, label, err)
+	}
+
+	return func(task *api.Task) bool {
+		for _, actualLabel := range taskLabels(task) {
+			if re.MatchString(actualLabel) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// compileGlob converts a glob pattern into a case-insensitive regular expression, where "*" matches
+// any sequence of characters, "?" matches any single character and "[...]" matches a character class
+// (negated with "[!...]" or "[^...]"). A backslash escapes the character that follows it.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+
+	builder.WriteString("(?is)^")
+
+	runes := []rune(pattern)
+
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		case '\\':
+			i++
+			if i == len(runes) {
+				return nil, errTrailingBackslash
+			}
+			builder.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			class, n, err := globClass(runes[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			builder.WriteString(class)
+			i += n
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+
+	builder.WriteString("$")
+
+	return regexp.Compile(builder.String())
+}
+
+// globClass converts the character class that follows the opening "[" into its regular
+// expression counterpart, returning the number of runes consumed, including the closing "]".
+func globClass(runes []rune) (string, int, error) {
+	var builder strings.Builder
+
+	builder.WriteString("[")
+
+	i := 0
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		builder.WriteString("^")
+		i++
+	}
+
+	for start := i; i < len(runes); i++ {
+		switch {
+		case runes[i] == ']' && i > start:
+			builder.WriteString("]")
+			return builder.String(), i + 1, nil
+		case runes[i] == '\\' && i+1 < len(runes):
+			i++
+			builder.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+
+	return "", 0, errUnterminatedClass
+}
+
+func MatchSelector(selector Selector) TaskFilter {
+	return func(tasks []*api.Task) ([]*api.Task, error) {
+		selected := make(map[int64]struct{})
+
+		for _, task := range tasks {
+			if selector.matches(task) {
+				selected[task.LocalGroupId] = struct{}{}
+			}
+		}
+
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("%w: none of the %d task(s) were matched using the specified selectors",
+				ErrNoMatch, len(tasks))
+		}
+
+		if selector.WithDependencies {
+			addDependencies(tasks, selected, selector.Exclude)
+		}
+
+		return MatchTaskIDs(idsOf(selected))(tasks)
+	}
+}
+
+func (selector Selector) matches(task *api.Task) bool {
+	if len(selector.Include) != 0 && !matchesAny(selector.Include, task) {
+		return false
+	}
+
+	for _, matcher := range selector.Require {
+		if !matcher(task) {
+			return false
+		}
+	}
+
+	return !matchesAny(selector.Exclude, task)
+}
+
+// addDependencies extends the selection with the transitive dependencies
+// of the selected tasks, except for the explicitly excluded ones.
+func addDependencies(tasks []*api.Task, selected map[int64]struct{}, exclude []Matcher) {
+	tasksByID := make(map[int64]*api.Task)
+	for _, task := range tasks {
+		tasksByID[task.LocalGroupId] = task
+	}
+
+	var queue []int64
+	for id := range selected {
+		queue = append(queue, id)
+	}
+
+	for len(queue) != 0 {
+		task := tasksByID[queue[0]]
+		queue = queue[1:]
+
+		for _, requiredGroup := range task.RequiredGroups {
+			if _, ok := selected[requiredGroup]; ok {
+				continue
+			}
+
+			requiredTask, ok := tasksByID[requiredGroup]
+			if !ok || matchesAny(exclude, requiredTask) {
+				continue
+			}
+
+			selected[requiredGroup] = struct{}{}
+			queue = append(queue, requiredGroup)
+		}
+	}
+}
+
+func matchesAny(matchers []Matcher, task *api.Task) bool {
+	for _, matcher := range matchers {
+		if matcher(task) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func idsOf(set map[int64]struct{}) (result []int64) {
+	for id := range set {
+		result = append(result, id)
+	}
+
+	return
+}
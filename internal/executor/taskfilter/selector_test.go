@@ -0,0 +1,127 @@
+package taskfilter_test
+
+import (
+	"errors"
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/taskfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func selectorTasks() []*api.Task {
+	return []*api.Task{
+		{LocalGroupId: 0, Name: "lint_go"},
+		{LocalGroupId: 1, Name: "lint_docs"},
+		{LocalGroupId: 2, Name: "build"},
+		{LocalGroupId: 3, Name: "test", RequiredGroups: []int64{2},
+			Metadata: &api.Task_Metadata{UniqueLabels: []string{"VERSION:1.15"}}},
+		{LocalGroupId: 4, Name: "test", RequiredGroups: []int64{2},
+			Metadata: &api.Task_Metadata{UniqueLabels: []string{"VERSION:1.14"}}},
+	}
+}
+
+func selectedIDs(tasks []*api.Task) (result []int64) {
+	for _, task := range tasks {
+		result = append(result, task.LocalGroupId)
+	}
+
+	return
+}
+
+func mustMatcher(t *testing.T) func(taskfilter.Matcher, error) taskfilter.Matcher {
+	return func(matcher taskfilter.Matcher, err error) taskfilter.Matcher {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return matcher
+	}
+}
+
+// TestSelectorGlobAndExclusion ensures that glob patterns and exclusions are respected.
+func TestSelectorGlobAndExclusion(t *testing.T) {
+	include := mustMatcher(t)(taskfilter.NameMatcher("LINT*"))
+	exclude := mustMatcher(t)(taskfilter.NameMatcher("lint_docs"))
+
+	tasks, err := taskfilter.MatchSelector(taskfilter.Selector{
+		Include: []taskfilter.Matcher{include},
+		Exclude: []taskfilter.Matcher{exclude},
+	})(selectorTasks())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{0}, selectedIDs(tasks))
+}
+
+// TestSelectorRegexAndLabels ensures that regular expressions and label patterns are respected.
+func TestSelectorRegexAndLabels(t *testing.T) {
+	include := mustMatcher(t)(taskfilter.RegexMatcher("^te"))
+	label := mustMatcher(t)(taskfilter.LabelMatcher("version:1.15"))
+
+	tasks, err := taskfilter.MatchSelector(taskfilter.Selector{
+		Include: []taskfilter.Matcher{include},
+		Require: []taskfilter.Matcher{label},
+	})(selectorTasks())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{3}, selectedIDs(tasks))
+	assert.Empty(t, tasks[0].RequiredGroups)
+}
+
+// TestSelectorWithDependencies ensures that transitive dependencies are pulled in when asked to.
+func TestSelectorWithDependencies(t *testing.T) {
+	include := mustMatcher(t)(taskfilter.NameMatcher("test VERSION:1.14"))
+
+	tasks, err := taskfilter.MatchSelector(taskfilter.Selector{
+		Include:          []taskfilter.Matcher{include},
+		WithDependencies: true,
+	})(selectorTasks())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{2, 4}, selectedIDs(tasks))
+	assert.Equal(t, []int64{2}, tasks[1].RequiredGroups)
+}
+
+// TestSelectorNoMatch ensures that an error is returned when nothing was selected.
+func TestSelectorNoMatch(t *testing.T) {
+	include := mustMatcher(t)(taskfilter.NameMatcher("deploy*"))
+
+	_, err := taskfilter.MatchSelector(taskfilter.Selector{
+		Include: []taskfilter.Matcher{include},
+	})(selectorTasks())
+	assert.True(t, errors.Is(err, taskfilter.ErrNoMatch))
+}
+
+// TestNameMatcherGlob ensures that the glob patterns match the whole task name,
+// including the "/" characters which are not special in the task names.
+func TestNameMatcherGlob(t *testing.T) {
+	testCases := []struct {
+		Pattern string
+		Name    string
+		Matched bool
+	}{
+		{"docker*", "docker/build", true},
+		{"*/build", "Docker/Build", true},
+		{"docker?build", "docker/build", true},
+		{"docker*", "build/docker", false},
+		{"test_[0-9]", "test_1", true},
+		{"test_[!0-9]", "test_1", false},
+		{"test_[^0-9]", "test_x", true},
+		{"lint.*", "lint_go", false},
+		{"\\*lint*", "*lint_go", true},
+	}
+
+	for _, testCase := range testCases {
+		matcher := mustMatcher(t)(taskfilter.NameMatcher(testCase.Pattern))
+		assert.Equal(t, testCase.Matched, matcher(&api.Task{Name: testCase.Name}),
+			"pattern %q, name %q", testCase.Pattern, testCase.Name)
+	}
+}
+
+// TestNameMatcherBadGlob ensures that malformed glob patterns are rejected.
+func TestNameMatcherBadGlob(t *testing.T) {
+	for _, pattern := range []string{"test_[0-9", "lint*\\"} {
+		_, err := taskfilter.NameMatcher(pattern)
+		assert.True(t, errors.Is(err, taskfilter.ErrInvalidSelector), "pattern %q", pattern)
+	}
+}
@@ -22,23 +22,7 @@ func MatchExactTask(desiredTaskName string) TaskFilter {
 		var filteredTasks []*api.Task
 
 		for _, task := range tasks {
-			// Ensure that this task's name matches with the name we're looking for
-			desiredTaskNameLower := strings.ToLower(desiredTaskName)
-			taskNameLower := strings.ToLower(task.Name)
-
-			if !strings.HasPrefix(desiredTaskNameLower, taskNameLower) {
-				continue
-			}
-
-			// In case we're looking for a task with specific labels — extract them and ensure they all match
-			desiredLabels := extractLabels(strings.TrimPrefix(desiredTaskNameLower, taskNameLower))
-
-			var actualLabels []string
-			if task.Metadata != nil {
-				actualLabels = task.Metadata.UniqueLabels
-			}
-
-			if !containsAll(actualLabels, desiredLabels) {
+			if !matchesExactTask(task, desiredTaskName) {
 				continue
 			}
 
@@ -57,6 +41,29 @@ func MatchExactTask(desiredTaskName string) TaskFilter {
 	}
 }
 
+func matchesExactTask(task *api.Task, desiredTaskName string) bool {
+	// Ensure that this task's name matches with the name we're looking for
+	desiredTaskNameLower := strings.ToLower(desiredTaskName)
+	taskNameLower := strings.ToLower(task.Name)
+
+	if !strings.HasPrefix(desiredTaskNameLower, taskNameLower) {
+		return false
+	}
+
+	// In case we're looking for a task with specific labels — extract them and ensure they all match
+	desiredLabels := extractLabels(strings.TrimPrefix(desiredTaskNameLower, taskNameLower))
+
+	return containsAll(taskLabels(task), desiredLabels)
+}
+
+func taskLabels(task *api.Task) []string {
+	if task.Metadata == nil {
+		return nil
+	}
+
+	return task.Metadata.UniqueLabels
+}
+
 func extractLabels(s string) (result []string) {
 	labels := strings.Split(s, " ")
 
@@ -86,3 +93,57 @@ func containsAll(actualLabels []string, desiredLabels []string) bool {
 
 	return numMatchedLabels == len(desiredLabels)
 }
+
+// MatchTaskIDs only keeps the tasks with the specified IDs. Dependencies on the tasks
+// that were filtered out are removed since these are considered to be already satisfied.
+func MatchTaskIDs(ids []int64) TaskFilter {
+	return func(tasks []*api.Task) ([]*api.Task, error) {
+		desiredIDs := make(map[int64]struct{})
+		for _, id := range ids {
+			desiredIDs[id] = struct{}{}
+		}
+
+		var filteredTasks []*api.Task
+
+		for _, task := range tasks {
+			if _, ok := desiredIDs[task.LocalGroupId]; ok {
+				filteredTasks = append(filteredTasks, task)
+			}
+		}
+
+		for _, task := range filteredTasks {
+			var requiredGroups []int64
+
+			for _, requiredGroup := range task.RequiredGroups {
+				if _, ok := desiredIDs[requiredGroup]; ok {
+					requiredGroups = append(requiredGroups, requiredGroup)
+				}
+			}
+
+			task.RequiredGroups = requiredGroups
+		}
+
+		if len(filteredTasks) == 0 {
+			return nil, fmt.Errorf("%w: none of the %d task(s) were matched by their IDs",
+				ErrNoMatch, len(tasks))
+		}
+
+		return filteredTasks, nil
+	}
+}
+
+// Chain applies the specified filters one after another.
+func Chain(filters ...TaskFilter) TaskFilter {
+	return func(tasks []*api.Task) ([]*api.Task, error) {
+		for _, filter := range filters {
+			var err error
+
+			tasks, err = filter(tasks)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return tasks, nil
+	}
+}
@@ -0,0 +1,44 @@
+package taskfilter_test
+
+import (
+	"github.com/cirruslabs/cirrus-ci-agent/api"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/taskfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// TestMatchTaskIDs ensures that the dependencies on the filtered out tasks are removed.
+func TestMatchTaskIDs(t *testing.T) {
+	tasks := []*api.Task{
+		{LocalGroupId: 0, Name: "build"},
+		{LocalGroupId: 1, Name: "test", RequiredGroups: []int64{0}},
+		{LocalGroupId: 2, Name: "deploy", RequiredGroups: []int64{0, 1}},
+	}
+
+	filteredTasks, err := taskfilter.MatchTaskIDs([]int64{1, 2})(tasks)
+	require.NoError(t, err)
+	require.Len(t, filteredTasks, 2)
+
+	assert.Equal(t, "test", filteredTasks[0].Name)
+	assert.Empty(t, filteredTasks[0].RequiredGroups)
+	assert.Equal(t, "deploy", filteredTasks[1].Name)
+	assert.Equal(t, []int64{1}, filteredTasks[1].RequiredGroups)
+}
+
+// TestChain ensures that the filters are applied one after another.
+func TestChain(t *testing.T) {
+	tasks := []*api.Task{
+		{LocalGroupId: 0, Name: "build"},
+		{LocalGroupId: 1, Name: "test"},
+		{LocalGroupId: 2, Name: "test"},
+	}
+
+	filteredTasks, err := taskfilter.Chain(
+		taskfilter.MatchTaskIDs([]int64{0, 2}),
+		taskfilter.MatchExactTask("test"),
+	)(tasks)
+	require.NoError(t, err)
+	require.Len(t, filteredTasks, 1)
+	assert.EqualValues(t, 2, filteredTasks[0].LocalGroupId)
+}
@@ -0,0 +1,118 @@
+package tasklogs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// LogSuffix is appended to the task and command names to form the log file names.
+const LogSuffix = ".log"
+
+var ErrInternal = errors.New("internal task logs storage error")
+
+// Store keeps the logs of the tasks in a <dir>/<task>.log files, with the logs of each
+// individual command additionally stored in a <dir>/<task>/<command>.log files.
+type Store struct {
+	dir  string
+	lock sync.Mutex
+}
+
+func New(dir string) *Store {
+	return &Store{
+		dir: dir,
+	}
+}
+
+func (store *Store) Dir() string {
+	return store.dir
+}
+
+// TaskLogPath returns the path to the file that holds the task's full log.
+func (store *Store) TaskLogPath(task string) string {
+	return filepath.Join(store.dir, sanitizeName(task)+LogSuffix)
+}
+
+// CommandLogPath returns the path to the file that holds the log of the task's command.
+func (store *Store) CommandLogPath(task, command string) string {
+	return filepath.Join(store.dir, sanitizeName(task), sanitizeName(command)+LogSuffix)
+}
+
+// Clear removes the logs left by the previous runs of the task.
+func (store *Store) Clear(task string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := os.Remove(store.TaskLogPath(task)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(store.dir, sanitizeName(task))); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// AppendCommandLog appends the chunk of the command's output to both the command's and the task's logs.
+func (store *Store) AppendCommandLog(task, command string, data []byte) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := appendFile(store.CommandLogPath(task, command), data); err != nil {
+		return err
+	}
+
+	return appendFile(store.TaskLogPath(task), data)
+}
+
+// AppendTaskLog appends a message that doesn't belong to any command (e.g. an agent warning) to the task's log.
+func (store *Store) AppendTaskLog(task, message string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	return appendFile(store.TaskLogPath(task), []byte(strings.TrimSuffix(message, "\n")+"\n"))
+}
+
+func appendFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+
+	return nil
+}
+
+// sanitizeName makes sure that the task and command names can be safely used as a single path component,
+// including on Windows, since the task descriptions include the task's labels, which may contain any characters.
+func sanitizeName(name string) string {
+	name = strings.Map(func(c rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, c) {
+			return '_'
+		}
+
+		return c
+	}, name)
+
+	if name == "" || name == "." || name == ".." {
+		name = strings.Repeat("_", len(name)+1)
+	}
+
+	return name
+}
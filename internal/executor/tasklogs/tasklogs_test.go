@@ -0,0 +1,45 @@
+package tasklogs_test
+
+import (
+	"github.com/cirruslabs/cirrus-cli/internal/executor/tasklogs"
+	"github.com/cirruslabs/cirrus-cli/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	return string(contents)
+}
+
+// TestStore ensures that the task's log contains the output of all of its commands
+// and the problems reported by the agent, while the command logs are kept separately.
+func TestStore(t *testing.T) {
+	dir := testutil.TempDir(t)
+	store := tasklogs.New(dir)
+
+	const task = "'main' task (container:golang)"
+
+	require.NoError(t, store.AppendCommandLog(task, "clone", []byte("Cloning...\n")))
+	require.NoError(t, store.AppendCommandLog(task, "build", []byte("Buil")))
+	require.NoError(t, store.AppendCommandLog(task, "build", []byte("ding...\n")))
+	require.NoError(t, store.AppendTaskLog(task, "agent warning: something's off"))
+
+	taskLogPath := filepath.Join(dir, "'main' task (container_golang).log")
+	assert.Equal(t, taskLogPath, store.TaskLogPath(task))
+	assert.Equal(t, "Cloning...\nBuilding...\nagent warning: something's off\n", readFile(t, taskLogPath))
+
+	assert.Equal(t, "Cloning...\n", readFile(t, store.CommandLogPath(task, "clone")))
+	assert.Equal(t, "Building...\n", readFile(t, store.CommandLogPath(task, "build")))
+
+	// Logs of the previous runs are removed
+	require.NoError(t, store.Clear(task))
+	assert.NoFileExists(t, taskLogPath)
+	assert.NoFileExists(t, store.CommandLogPath(task, "build"))
+	require.NoError(t, store.Clear(task))
+}
@@ -0,0 +1,195 @@
+package trace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+const (
+	FormatChrome = "chrome"
+	FormatOTLP   = "otlp"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported trace format")
+
+// Formats returns the supported trace formats.
+func Formats() []string {
+	return []string{FormatChrome, FormatOTLP}
+}
+
+// ValidateFormat returns an error if the trace format is not supported.
+func ValidateFormat(format string) error {
+	for _, supportedFormat := range Formats() {
+		if format == supportedFormat {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// Write exports the recorded spans in the specified format.
+func (tracer *Tracer) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatChrome:
+		return tracer.WriteChrome(w)
+	case FormatOTLP:
+		return tracer.WriteOTLP(w)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+type chromeEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat,omitempty"`
+	Phase     string            `json:"ph"`
+	Timestamp float64           `json:"ts"`
+	Duration  float64           `json:"dur,omitempty"`
+	PID       int64             `json:"pid"`
+	TID       int64             `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+// WriteChrome exports the recorded spans in the Chrome Trace Event format[1]
+// which can be opened in Perfetto UI or chrome://tracing.
+//
+// [1]: https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+func (tracer *Tracer) WriteChrome(w io.Writer) error {
+	spans, laneNames := tracer.snapshot()
+
+	events := []chromeEvent{}
+
+	var lanes []int64
+	for lane := range laneNames {
+		lanes = append(lanes, lane)
+	}
+	sort.Slice(lanes, func(i, j int) bool { return lanes[i] < lanes[j] })
+
+	for _, lane := range lanes {
+		events = append(events, chromeEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			PID:   1,
+			TID:   lane,
+			Args:  map[string]string{"name": laneNames[lane]},
+		})
+	}
+
+	const nanosecondsInMicrosecond = 1000.0
+
+	for _, span := range spans {
+		events = append(events, chromeEvent{
+			Name:      span.Name,
+			Category:  "cirrus",
+			Phase:     "X",
+			Timestamp: float64(span.Start.Sub(tracer.startTime).Nanoseconds()) / nanosecondsInMicrosecond,
+			Duration:  float64(span.End.Sub(span.Start).Nanoseconds()) / nanosecondsInMicrosecond,
+			PID:       1,
+			TID:       span.Lane,
+			Args:      span.Attributes,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+}
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	result := []otlpAttribute{}
+
+	var keys []string
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result = append(result, otlpAttribute{Key: key, Value: otlpValue{StringValue: attributes[key]}})
+	}
+
+	return result
+}
+
+// WriteOTLP exports the recorded spans in the OTLP JSON format[1]
+// which can be imported by OpenTelemetry-compatible tools (e.g. Jaeger).
+//
+// [1]: https://github.com/open-telemetry/opentelemetry-proto/blob/main/docs/specification.md#json-protobuf-encoding
+func (tracer *Tracer) WriteOTLP(w io.Writer) error {
+	spans, laneNames := tracer.snapshot()
+
+	const spanKindInternal = 1
+
+	otlpSpans := []otlpSpan{}
+
+	for _, span := range spans {
+		attributes := span.Attributes
+		if laneName, ok := laneNames[span.Lane]; ok {
+			attributes["lane"] = laneName
+		}
+
+		otlpSpan := otlpSpan{
+			TraceID:           tracer.traceID,
+			SpanID:            span.id,
+			Name:              span.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(attributes),
+		}
+
+		if span.parent != nil {
+			otlpSpan.ParentSpanID = span.parent.id
+		}
+
+		otlpSpans = append(otlpSpans, otlpSpan)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]string{"service.name": "cirrus-cli"}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "cirrus-cli"},
+						"spans": otlpSpans,
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,237 @@
+// Package trace records the timeline of a build as a set of spans
+// that can be exported in the Chrome Trace Event and OTLP JSON formats.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	traceIDSize = 16
+	spanIDSize  = 8
+)
+
+// BuildLane is the lane used for the spans that are not specific to any task.
+const BuildLane int64 = 0
+
+// TaskLane returns the lane used for the spans related to the specified task.
+func TaskLane(taskID int64) int64 {
+	return taskID + 1
+}
+
+type Tracer struct {
+	mutex     sync.Mutex
+	traceID   string
+	startTime time.Time
+	spans     []*Span
+	laneNames map[int64]string
+	laneRoots map[int64]*Span
+}
+
+type Span struct {
+	tracer *Tracer
+
+	id     string
+	parent *Span
+
+	Name       string
+	Lane       int64
+	Start, End time.Time
+	Attributes map[string]string
+}
+
+func New() *Tracer {
+	return &Tracer{
+		traceID:   randomID(traceIDSize),
+		startTime: time.Now(),
+		laneNames: make(map[int64]string),
+		laneRoots: make(map[int64]*Span),
+	}
+}
+
+func randomID(size int) string {
+	buf := make([]byte, size)
+	_, _ = rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
+
+type tracerKey struct{}
+type spanKey struct{}
+type laneKey struct{}
+
+// WithTracer returns a context that carries the tracer for the Start() to use.
+func WithTracer(ctx context.Context, tracer *Tracer) context.Context {
+	if tracer == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// FromContext returns the tracer carried by the context or nil if there's none.
+func FromContext(ctx context.Context) *Tracer {
+	tracer, _ := ctx.Value(tracerKey{}).(*Tracer)
+
+	return tracer
+}
+
+// WithLane returns a context in which the new spans are placed in the specified lane.
+func WithLane(ctx context.Context, lane int64, name string) context.Context {
+	FromContext(ctx).SetLaneName(lane, name)
+
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+// Start starts a new span that is a child of the span carried by the context (if any).
+// When the context carries no tracer, a nil span is returned, which is safe to use.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	tracer := FromContext(ctx)
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	lane, ok := ctx.Value(laneKey{}).(int64)
+	if !ok {
+		lane = BuildLane
+	}
+
+	parent, _ := ctx.Value(spanKey{}).(*Span)
+
+	span := tracer.newSpan(name, lane, parent, time.Now())
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetLaneName sets a human-readable name for the lane.
+func (tracer *Tracer) SetLaneName(lane int64, name string) {
+	if tracer == nil {
+		return
+	}
+
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	tracer.laneNames[lane] = name
+}
+
+// Record adds an already finished span to the lane, which is useful for the events
+// whose timing is only known afterwards (e.g. the commands reported by the agent).
+func (tracer *Tracer) Record(name string, lane int64, start, end time.Time, attributes map[string]string) {
+	if tracer == nil {
+		return
+	}
+
+	span := tracer.newSpan(name, lane, nil, start)
+	for key, value := range attributes {
+		span.SetAttribute(key, value)
+	}
+	span.finish(end)
+}
+
+// StartInLane starts a new span in the lane, which is useful when there's no context to derive
+// the span from (e.g. in RPC handlers).
+func (tracer *Tracer) StartInLane(name string, lane int64) *Span {
+	if tracer == nil {
+		return nil
+	}
+
+	return tracer.newSpan(name, lane, nil, time.Now())
+}
+
+func (tracer *Tracer) newSpan(name string, lane int64, parent *Span, start time.Time) *Span {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	// Spans without a parent in the same lane belong to the first span in their lane,
+	// and the first span in the lane belongs to the first span in the build lane
+	if parent == nil || parent.Lane != lane {
+		parent = tracer.laneRoots[lane]
+	}
+	if parent == nil {
+		parent = tracer.laneRoots[BuildLane]
+	}
+
+	span := &Span{
+		tracer:     tracer,
+		id:         randomID(spanIDSize),
+		parent:     parent,
+		Name:       name,
+		Lane:       lane,
+		Start:      start,
+		Attributes: make(map[string]string),
+	}
+
+	if _, ok := tracer.laneRoots[lane]; !ok {
+		tracer.laneRoots[lane] = span
+	}
+
+	tracer.spans = append(tracer.spans, span)
+
+	return span
+}
+
+// SetAttribute attaches additional information to the span.
+func (span *Span) SetAttribute(key string, value string) {
+	if span == nil {
+		return
+	}
+
+	span.tracer.mutex.Lock()
+	defer span.tracer.mutex.Unlock()
+
+	span.Attributes[key] = value
+}
+
+// End marks the span as finished.
+func (span *Span) End() {
+	if span == nil {
+		return
+	}
+
+	span.finish(time.Now())
+}
+
+func (span *Span) finish(end time.Time) {
+	span.tracer.mutex.Lock()
+	defer span.tracer.mutex.Unlock()
+
+	if span.End.IsZero() {
+		span.End = end
+	}
+}
+
+// snapshot returns a copy of the spans with unfinished spans ending now.
+func (tracer *Tracer) snapshot() ([]Span, map[int64]string) {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	now := time.Now()
+
+	var spans []Span
+	for _, span := range tracer.spans {
+		spanCopy := *span
+
+		spanCopy.Attributes = make(map[string]string)
+		for key, value := range span.Attributes {
+			spanCopy.Attributes[key] = value
+		}
+
+		if spanCopy.End.IsZero() {
+			spanCopy.End = now
+		}
+
+		spans = append(spans, spanCopy)
+	}
+
+	laneNames := make(map[int64]string)
+	for lane, name := range tracer.laneNames {
+		laneNames[lane] = name
+	}
+
+	return spans, laneNames
+}
@@ -0,0 +1,120 @@
+package trace_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/cirruslabs/cirrus-cli/internal/executor/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTracer() *trace.Tracer {
+	tracer := trace.New()
+
+	ctx := trace.WithLane(trace.WithTracer(context.Background(), tracer), trace.BuildLane, "build")
+	ctx, buildSpan := trace.Start(ctx, "build")
+
+	taskCtx := trace.WithLane(ctx, trace.TaskLane(0), "main (0)")
+	taskCtx, taskSpan := trace.Start(taskCtx, "task main (0)")
+
+	_, pullSpan := trace.Start(taskCtx, "image pull")
+	pullSpan.SetAttribute("image", "debian:latest")
+	pullSpan.End()
+
+	now := time.Now()
+	tracer.Record("command main", trace.TaskLane(0), now.Add(-time.Second), now, map[string]string{
+		"status": "success",
+	})
+
+	taskSpan.End()
+	buildSpan.End()
+
+	return tracer
+}
+
+// TestNoTracer ensures that spans are safe to use when the tracing is disabled.
+func TestNoTracer(t *testing.T) {
+	_, span := trace.Start(context.Background(), "build")
+	assert.Nil(t, span)
+
+	span.SetAttribute("key", "value")
+	span.End()
+}
+
+// TestChrome ensures that all spans and lane names are exported in the Chrome Trace Event format.
+func TestChrome(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, newTracer().Write(buf, trace.FormatChrome))
+
+	var decoded struct {
+		TraceEvents []struct {
+			Name  string            `json:"name"`
+			Phase string            `json:"ph"`
+			TID   int64             `json:"tid"`
+			Args  map[string]string `json:"args"`
+		} `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+	var laneNames, spanNames []string
+	for _, event := range decoded.TraceEvents {
+		switch event.Phase {
+		case "M":
+			laneNames = append(laneNames, event.Args["name"])
+		case "X":
+			spanNames = append(spanNames, event.Name)
+		}
+
+		if event.Name == "image pull" {
+			assert.EqualValues(t, trace.TaskLane(0), event.TID)
+			assert.Equal(t, "debian:latest", event.Args["image"])
+		}
+	}
+
+	assert.Equal(t, []string{"build", "main (0)"}, laneNames)
+	assert.Equal(t, []string{"build", "task main (0)", "image pull", "command main"}, spanNames)
+}
+
+// TestOTLP ensures that the span hierarchy is preserved in the OTLP JSON format.
+func TestOTLP(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, newTracer().Write(buf, trace.FormatOTLP))
+
+	var decoded struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					SpanID       string `json:"spanId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Name         string `json:"name"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+	spans := decoded.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 4)
+
+	parents := make(map[string]string)
+	names := make(map[string]string)
+	for _, span := range spans {
+		names[span.SpanID] = span.Name
+		parents[span.Name] = span.ParentSpanID
+	}
+
+	assert.Empty(t, parents["build"])
+	assert.Equal(t, "build", names[parents["task main (0)"]])
+	assert.Equal(t, "task main (0)", names[parents["image pull"]])
+	assert.Equal(t, "task main (0)", names[parents["command main"]])
+}
+
+// TestUnsupportedFormat ensures that unknown formats are rejected.
+func TestUnsupportedFormat(t *testing.T) {
+	assert.True(t, errors.Is(trace.ValidateFormat("xml"), trace.ErrUnsupportedFormat))
+	assert.True(t, errors.Is(newTracer().Write(&bytes.Buffer{}, "xml"), trace.ErrUnsupportedFormat))
+}
@@ -52,6 +52,7 @@ type Parser struct {
 
 	tasksCountBeforeFiltering   int64
 	disabledTaskNamesAndAliases map[string]struct{}
+	disabledTasks               []*DisabledTask
 
 	issues []*api.Issue
 }
@@ -63,6 +64,19 @@ type Result struct {
 	// A helper field that lets some external post-processor
 	// to inject new tasks correctly (e.g. Dockerfile build tasks)
 	TasksCountBeforeFiltering int64
+
+	// Tasks that were filtered out due to their "only_if" expressions
+	DisabledTasks []*DisabledTask
+}
+
+// DisabledTask describes a task that was filtered out due to its "only_if" expression.
+type DisabledTask struct {
+	Name           string
+	Alias          string
+	DependsOnNames []string
+
+	// IDs of the enabled tasks that depend on this task
+	RequiredBy []int64
 }
 
 func New(opts ...Option) *Parser {
@@ -166,6 +180,11 @@ func (p *Parser) parseTasks(tree *node.Node) ([]task.ParseableTaskLike, error) {
 			if !enabled {
 				p.disabledTaskNamesAndAliases[taskLike.Name()] = struct{}{}
 				p.disabledTaskNamesAndAliases[taskLike.Alias()] = struct{}{}
+				p.disabledTasks = append(p.disabledTasks, &DisabledTask{
+					Name:           taskLike.Name(),
+					Alias:          taskLike.Alias(),
+					DependsOnNames: taskLike.DependsOnNames(),
+				})
 				continue
 			}
 
@@ -208,7 +227,7 @@ func (p *Parser) Parse(ctx context.Context, config string) (result *Result, err
 	}
 
 	if len(tasks) == 0 {
-		return &Result{Issues: p.issues}, nil
+		return &Result{Issues: p.issues, DisabledTasks: p.disabledTasks}, nil
 	}
 
 	if err := validateDependenciesDeep(tasks); err != nil {
@@ -255,6 +274,7 @@ func (p *Parser) Parse(ctx context.Context, config string) (result *Result, err
 		Tasks:                     protoTasks,
 		TasksCountBeforeFiltering: p.tasksCountBeforeFiltering,
 		Issues:                    p.issues,
+		DisabledTasks:             p.disabledTasks,
 	}, nil
 }
 
@@ -330,6 +350,12 @@ func (p *Parser) resolveDependenciesShallow(tasks []task.ParseableTaskLike) erro
 		for _, dependsOnName := range task.DependsOnNames() {
 			// Dependency may be missing due to only_if
 			if _, ok := p.disabledTaskNamesAndAliases[dependsOnName]; ok {
+				for _, disabledTask := range p.disabledTasks {
+					if disabledTask.Name == dependsOnName || disabledTask.Alias == dependsOnName {
+						disabledTask.RequiredBy = append(disabledTask.RequiredBy, task.ID())
+					}
+				}
+
 				continue
 			}
 
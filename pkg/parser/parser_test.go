@@ -449,6 +449,22 @@ func TestTasksCountBeforeFiltering(t *testing.T) {
 	assert.EqualValues(t, 2, result.TasksCountBeforeFiltering)
 }
 
+func TestDisabledTasks(t *testing.T) {
+	p := parser.New()
+	result, err := p.ParseFromFile(context.Background(), "testdata/disabled-tasks.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, result.Tasks, 2)
+	require.Len(t, result.DisabledTasks, 1)
+
+	disabledTask := result.DisabledTasks[0]
+	assert.Equal(t, "deploy", disabledTask.Name)
+	assert.Equal(t, []string{"build"}, disabledTask.DependsOnNames)
+	assert.Equal(t, []int64{result.Tasks[1].LocalGroupId}, disabledTask.RequiredBy)
+}
+
 func TestRichErrors(t *testing.T) {
 	testCases := []struct {
 		File  string